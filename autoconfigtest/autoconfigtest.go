@@ -0,0 +1,502 @@
+// Package autoconfigtest drives autoconfig forms from Go tests, without a
+// display or a human.
+//
+// Qt widgets can only be used from the thread that created the QApplication,
+// so the test binary must be started with Main:
+//
+//	func TestMain(m *testing.M) {
+//		autoconfigtest.Main(m)
+//	}
+//
+// Then, each test builds a form for its struct, sets values by the field's
+// path (see schema.Schema.Lookup), and saves the form:
+//
+//	cfg := Config{}
+//	form := autoconfigtest.New(t, &cfg)
+//	form.SetField("Network.Port", 8080)
+//
+//	peer := form.Add("Peers") // Opens the nested dialog
+//	peer.SetField("Name", "example")
+//	peer.Save()
+//
+//	form.Save()
+//
+// The offscreen QPA platform is used, unless the QT_QPA_PLATFORM environment
+// variable is already set.
+package autoconfigtest
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/mappu/autoconfig"
+	qt "github.com/mappu/miqt/qt6"
+	"github.com/mappu/miqt/qt6/mainthread"
+)
+
+func init() {
+	// Keep the main goroutine on the main thread, for Qt
+	runtime.LockOSThread()
+}
+
+// Main creates the QApplication and runs the tests, then exits. Call it from
+// TestMain.
+// The Qt event loop runs on the main thread while the tests run, and every
+// Form method runs its work there.
+func Main(m *testing.M) {
+	if os.Getenv("QT_QPA_PLATFORM") == "" {
+		os.Setenv("QT_QPA_PLATFORM", "offscreen")
+	}
+
+	qt.NewQApplication(os.Args)
+
+	code := make(chan int, 1)
+	go func() {
+		code <- m.Run()
+		mainthread.Start(qt.QCoreApplication_Quit)
+	}()
+
+	qt.QApplication_Exec()
+	os.Exit(<-code)
+}
+
+// Form is a form for a configurable struct, or a nested dialog that was opened
+// from one.
+// Any problem, e.g. a field that can't be found, fails the test.
+type Form struct {
+	t      testing.TB
+	window *qt.QWidget        // Contains the form's widgets
+	editor *autoconfig.Editor // For the top-level form
+	dialog *qt.QDialog        // For a nested dialog
+}
+
+// New builds a form for the struct, in a hidden window. The window is deleted
+// when the test finishes.
+func New(t testing.TB, ct autoconfig.ConfigurableStruct, opts ...autoconfig.Option) *Form {
+	t.Helper()
+
+	f := &Form{t: t}
+	mainthread.Wait(func() {
+		f.window = qt.NewQWidget2()
+		area := qt.NewQFormLayout(f.window)
+		f.editor = autoconfig.MakeEditor(ct, area, opts...)
+	})
+
+	t.Cleanup(func() {
+		mainthread.Wait(f.window.DeleteLater) // Also deletes any nested dialogs
+	})
+
+	return f
+}
+
+// do runs the function on the Qt thread, and fails the test if it returns an
+// error.
+func (f *Form) do(fn func() error) {
+	f.t.Helper()
+
+	var err error
+	mainthread.Wait(func() { err = fn() })
+	if err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+// SetField sets the value of the field at the path, in the same way as a user
+// would, e.g. "Network.Port".
+//
+// Text fields accept any value, which is formatted with fmt.Sprint. Checkboxes
+// need a bool, number fields need a number, and time fields need a time.Time.
+// Dropdowns need the option's text. For fields with several inputs, e.g. a
+// Factor or an AddressPort, the value is set in the first one.
+func (f *Form) SetField(path string, value any) {
+	f.t.Helper()
+	f.do(func() error {
+		w, err := f.find(path)
+		if err != nil {
+			return err
+		}
+
+		if err := setValue(w, value); err != nil {
+			return fmt.Errorf("field %q: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// Text gets the text that is shown for the field at the path. Checkboxes show
+// "true" or "false".
+func (f *Form) Text(path string) string {
+	f.t.Helper()
+
+	var ret string
+	f.do(func() error {
+		w, err := f.find(path)
+		if err != nil {
+			return err
+		}
+
+		if ret, err = text(w); err != nil {
+			return fmt.Errorf("field %q: %w", path, err)
+		}
+		return nil
+	})
+	return ret
+}
+
+// Choose selects an option of the OneOf field at the path, by the name of the
+// option's struct field.
+func (f *Form) Choose(path, option string) {
+	f.t.Helper()
+	f.do(func() error {
+		w, err := f.find(path)
+		if err != nil {
+			return err
+		}
+		if !w.Inherits("QComboBox") {
+			return fmt.Errorf("field %q is not a OneOf", path)
+		}
+		if !w.IsEnabled() {
+			return fmt.Errorf("field %q is read-only", path)
+		}
+
+		picker := qt.UnsafeNewQComboBox(w.UnsafePointer())
+		idx := picker.FindData(qt.NewQVariant11(option))
+		if idx == -1 {
+			return fmt.Errorf("field %q has no option %q", path, option)
+		}
+
+		picker.SetCurrentIndex(idx)
+		picker.Activated(idx)
+		return nil
+	})
+}
+
+// Add clicks the add button of the slice or map field at the path, and returns
+// the dialog for the new item. Save the dialog to add the item.
+func (f *Form) Add(path string) *Form {
+	f.t.Helper()
+	return f.openDialog(path, "add", -1)
+}
+
+// Edit opens the item at the index in the slice or map field at the path, and
+// returns its dialog. Map items are in the order that they are shown.
+func (f *Form) Edit(path string, index int) *Form {
+	f.t.Helper()
+	return f.openDialog(path, "edit", index)
+}
+
+// Open opens the pointer field at the path, and returns its dialog.
+func (f *Form) Open(path string) *Form {
+	f.t.Helper()
+	return f.openDialog(path, "edit", -1)
+}
+
+// Remove removes the item at the index from the slice or map field at the path.
+func (f *Form) Remove(path string, index int) {
+	f.t.Helper()
+	f.do(func() error {
+		w, err := f.find(path)
+		if err != nil {
+			return err
+		}
+		if err := selectItem(w, index); err != nil {
+			return fmt.Errorf("field %q: %w", path, err)
+		}
+		if err := click(w, "remove"); err != nil {
+			return fmt.Errorf("field %q: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// Save saves the form into the struct. For a nested dialog, it clicks "OK",
+// which saves the value into the form that opened it.
+func (f *Form) Save() {
+	f.t.Helper()
+	f.do(func() error {
+		if f.dialog != nil {
+			if !f.dialog.IsVisible() {
+				return fmt.Errorf("the dialog was closed already")
+			}
+			f.dialog.Accept()
+			return nil
+		}
+
+		f.editor.Save()
+		return nil
+	})
+}
+
+// SaveChanges saves the top-level form into the struct, and describes the
+// changes since the form was built, or since the last SaveChanges.
+func (f *Form) SaveChanges() autoconfig.ChangeSet {
+	f.t.Helper()
+
+	var ret autoconfig.ChangeSet
+	f.do(func() error {
+		if f.editor == nil {
+			return fmt.Errorf("SaveChanges is only available for the top-level form")
+		}
+		ret = f.editor.SaveChanges()
+		return nil
+	})
+	return ret
+}
+
+// openDialog optionally selects an item in the field at the path, then clicks
+// a button that opens a nested dialog.
+// Dialogs that open as a page in a tree layout dialog are not supported.
+func (f *Form) openDialog(path, button string, index int) *Form {
+	f.t.Helper()
+
+	ret := &Form{t: f.t}
+	f.do(func() error {
+		w, err := f.find(path)
+		if err != nil {
+			return err
+		}
+		if index != -1 {
+			if err := selectItem(w, index); err != nil {
+				return fmt.Errorf("field %q: %w", path, err)
+			}
+		}
+
+		before := make(map[unsafe.Pointer]bool)
+		for _, dlg := range openDialogs() {
+			before[dlg.UnsafePointer()] = true
+		}
+
+		if err := click(w, button); err != nil {
+			return fmt.Errorf("field %q: %w", path, err)
+		}
+
+		for _, dlg := range openDialogs() {
+			if !before[dlg.UnsafePointer()] {
+				ret.window = dlg.QWidget
+				ret.dialog = dlg
+				return nil
+			}
+		}
+		return fmt.Errorf("field %q: the %s button did not open a dialog", path, button)
+	})
+	return ret
+}
+
+// find finds the widget for the field at the path.
+func (f *Form) find(path string) (*qt.QWidget, error) {
+	if w := findChild(f.window.QObject, autoconfig.ObjectName(path)); w != nil {
+		return w, nil
+	}
+	return nil, fmt.Errorf("no field %q in the form", path)
+}
+
+// findChild finds a descendant widget by its object name. Nested dialogs are
+// not searched, because they are separate forms.
+func findChild(obj *qt.QObject, name string) *qt.QWidget {
+	for _, child := range obj.Children() {
+		if !child.IsWidgetType() || child.Inherits("QDialog") {
+			continue
+		}
+		if child.ObjectName() == name {
+			return qt.UnsafeNewQWidget(child.UnsafePointer())
+		}
+		if ret := findChild(child, name); ret != nil {
+			return ret
+		}
+	}
+	return nil
+}
+
+// openDialogs gets all the dialogs that are currently shown.
+func openDialogs() []*qt.QDialog {
+	var ret []*qt.QDialog
+	for _, w := range qt.QApplication_TopLevelWidgets() {
+		if w.IsVisible() && w.Inherits("QDialog") {
+			ret = append(ret, qt.UnsafeNewQDialog(w.UnsafePointer()))
+		}
+	}
+	return ret
+}
+
+// click clicks a button in the field's widget, e.g. "add".
+func click(w *qt.QWidget, name string) error {
+	btn := findChild(w.QObject, name)
+	if btn == nil {
+		return fmt.Errorf("no %s button", name)
+	}
+	if !btn.IsEnabled() {
+		return fmt.Errorf("the %s button is disabled", name)
+	}
+
+	qt.UnsafeNewQAbstractButton(btn.UnsafePointer()).Click()
+	return nil
+}
+
+// selectItem selects only the item at the index in the field's list.
+func selectItem(w *qt.QWidget, index int) error {
+	var list *qt.QTreeWidget
+	for _, child := range w.Children() {
+		if child.Inherits("QTreeWidget") {
+			list = qt.UnsafeNewQTreeWidget(child.UnsafePointer())
+			break
+		}
+	}
+	if list == nil {
+		return fmt.Errorf("not a slice or map")
+	}
+
+	if index < 0 || index >= list.TopLevelItemCount() {
+		return fmt.Errorf("no item %d, there are %d items", index, list.TopLevelItemCount())
+	}
+
+	list.ClearSelection()
+	list.SetCurrentItem(list.TopLevelItem(index))
+	return nil
+}
+
+// setValue sets the value of a field's widget, and signals that it was edited.
+func setValue(w *qt.QWidget, value any) error {
+	if proxy := w.FocusProxy(); proxy != nil {
+		w = proxy // The first input of a field with several inputs
+	}
+	if !w.IsEnabled() {
+		return fmt.Errorf("read-only")
+	}
+
+	ptr := w.UnsafePointer()
+	rv := reflect.ValueOf(value)
+
+	switch {
+	case w.Inherits("QCheckBox"):
+		checked, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("need a bool, got %T", value)
+		}
+
+		btn := qt.UnsafeNewQAbstractButton(ptr)
+		if btn.IsChecked() != checked {
+			btn.Click()
+		}
+
+	case w.Inherits("QComboBox"):
+		combo := qt.UnsafeNewQComboBox(ptr)
+		idx := combo.FindText(fmt.Sprint(value))
+		if idx == -1 {
+			return fmt.Errorf("no option %q", fmt.Sprint(value))
+		}
+
+		combo.SetCurrentIndex(idx)
+		combo.Activated(idx)
+
+	case w.Inherits("QDateTimeEdit"):
+		t, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("need a time.Time, got %T", value)
+		}
+
+		edit := qt.UnsafeNewQDateTimeEdit(ptr)
+		if edit.IsReadOnly() {
+			return fmt.Errorf("read-only")
+		}
+		edit.SetDateTime(qt.QDateTime_FromMSecsSinceEpoch(t.UnixMilli()))
+		edit.EditingFinished()
+
+	case w.Inherits("QSpinBox"):
+		if !rv.CanInt() && !rv.CanUint() {
+			return fmt.Errorf("need an integer, got %T", value)
+		}
+
+		spin := qt.UnsafeNewQSpinBox(ptr)
+		if spin.IsReadOnly() {
+			return fmt.Errorf("read-only")
+		}
+		if rv.CanInt() {
+			spin.SetValue(int(rv.Int()))
+		} else {
+			spin.SetValue(int(rv.Uint()))
+		}
+		spin.EditingFinished()
+
+	case w.Inherits("QDoubleSpinBox"):
+		if !rv.CanFloat() && !rv.CanInt() {
+			return fmt.Errorf("need a number, got %T", value)
+		}
+
+		spin := qt.UnsafeNewQDoubleSpinBox(ptr)
+		if spin.IsReadOnly() {
+			return fmt.Errorf("read-only")
+		}
+		if rv.CanFloat() {
+			spin.SetValue(rv.Float())
+		} else {
+			spin.SetValue(float64(rv.Int()))
+		}
+		spin.EditingFinished()
+
+	case w.Inherits("QAbstractSpinBox"):
+		// The custom 64-bit spin boxes, which parse the edited text
+		if !rv.CanInt() && !rv.CanUint() {
+			return fmt.Errorf("need an integer, got %T", value)
+		}
+
+		spin := qt.UnsafeNewQAbstractSpinBox(ptr)
+		if spin.IsReadOnly() {
+			return fmt.Errorf("read-only")
+		}
+		text := fmt.Sprint(value)
+		spin.LineEdit().SetText(text)
+		spin.LineEdit().TextEdited(text)
+		spin.EditingFinished()
+
+	case w.Inherits("QLineEdit"):
+		edit := qt.UnsafeNewQLineEdit(ptr)
+		if edit.IsReadOnly() {
+			return fmt.Errorf("read-only")
+		}
+		edit.SetText(fmt.Sprint(value))
+		edit.EditingFinished()
+
+	case w.Inherits("QTextEdit"):
+		edit := qt.UnsafeNewQTextEdit(ptr)
+		if edit.IsReadOnly() {
+			return fmt.Errorf("read-only")
+		}
+		edit.SetPlainText(fmt.Sprint(value))
+
+	default:
+		return fmt.Errorf("can't set a value in a %s", w.MetaObject().ClassName())
+	}
+
+	return nil
+}
+
+// text gets the text shown in a field's widget.
+func text(w *qt.QWidget) (string, error) {
+	if proxy := w.FocusProxy(); proxy != nil {
+		w = proxy
+	}
+
+	ptr := w.UnsafePointer()
+
+	switch {
+	case w.Inherits("QCheckBox"):
+		return fmt.Sprint(qt.UnsafeNewQAbstractButton(ptr).IsChecked()), nil
+	case w.Inherits("QComboBox"):
+		return qt.UnsafeNewQComboBox(ptr).CurrentText(), nil
+	case w.Inherits("QAbstractSpinBox"):
+		return qt.UnsafeNewQAbstractSpinBox(ptr).Text(), nil
+	case w.Inherits("QLineEdit"):
+		return qt.UnsafeNewQLineEdit(ptr).Text(), nil
+	case w.Inherits("QTextEdit"):
+		return qt.UnsafeNewQTextEdit(ptr).ToPlainText(), nil
+	case w.Inherits("QLabel"):
+		return qt.UnsafeNewQLabel(ptr).Text(), nil
+	}
+
+	return "", fmt.Errorf("can't get the text of a %s", w.MetaObject().ClassName())
+}
@@ -0,0 +1,137 @@
+package autoconfigtest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mappu/autoconfig"
+	"github.com/mappu/autoconfig/schema"
+)
+
+type testNetwork struct {
+	Host string
+	Port int
+}
+
+type testPeer struct {
+	Name    string
+	Enabled bool
+}
+
+type testMemory struct {
+	Size int
+}
+
+type testDisk struct {
+	Path string
+}
+
+type testBackend struct {
+	Type   autoconfig.OneOf
+	Memory *testMemory
+	Disk   *testDisk
+}
+
+type testLimits struct {
+	MaxConns int
+}
+
+type testConfig struct {
+	Network testNetwork
+	Peers   []testPeer
+	Labels  map[string]string
+	Backend testBackend
+	Limits  *testLimits
+}
+
+func TestMain(m *testing.M) {
+	Main(m)
+}
+
+func TestForm(t *testing.T) {
+	cfg := testConfig{
+		Peers: []testPeer{{Name: "first"}},
+	}
+
+	form := New(t, &cfg)
+	form.SetField("Network.Host", "example.com")
+	form.SetField("Network.Port", 8080)
+
+	peer := form.Add("Peers")
+	peer.SetField("Name", "second")
+	peer.SetField("Enabled", true)
+	peer.Save()
+
+	first := form.Edit("Peers", 0)
+	if got := first.Text("Name"); got != "first" {
+		t.Errorf("Edit: got %q, want %q", got, "first")
+	}
+	first.SetField("Name", "renamed")
+	first.Save()
+
+	label := form.Add("Labels")
+	label.SetField("Key", "env")
+	label.SetField("Value", "prod")
+	label.Save()
+
+	form.Choose("Backend", "Disk")
+	form.SetField("Backend.Disk.Path", "/tmp/data")
+
+	limits := form.Open("Limits")
+	limits.SetField("MaxConns", 10)
+	limits.Save()
+
+	form.Save()
+
+	expect := testConfig{
+		Network: testNetwork{Host: "example.com", Port: 8080},
+		Peers:   []testPeer{{Name: "renamed"}, {Name: "second", Enabled: true}},
+		Labels:  map[string]string{"env": "prod"},
+		Backend: testBackend{Type: "Disk", Disk: &testDisk{Path: "/tmp/data"}},
+		Limits:  &testLimits{MaxConns: 10},
+	}
+	if !reflect.DeepEqual(cfg, expect) {
+		t.Errorf("got %+v, want %+v", cfg, expect)
+	}
+}
+
+func TestFormRemove(t *testing.T) {
+	cfg := testConfig{
+		Peers: []testPeer{{Name: "first"}, {Name: "second"}},
+	}
+
+	form := New(t, &cfg)
+	form.Remove("Peers", 0)
+	changes := form.SaveChanges()
+
+	if len(cfg.Peers) != 1 || cfg.Peers[0].Name != "second" {
+		t.Errorf("got %+v", cfg.Peers)
+	}
+	if len(changes) != 1 || changes[0].Path != "Peers[0]" || changes[0].Kind != schema.ChangeRemoved {
+		t.Errorf("SaveChanges: got %v", changes)
+	}
+}
+
+func TestFormUnsaved(t *testing.T) {
+	cfg := testConfig{
+		Peers: []testPeer{{Name: "first"}},
+	}
+
+	form := New(t, &cfg)
+	form.SetField("Network.Host", "example.com")
+	form.Remove("Peers", 0)
+
+	limits := form.Open("Limits")
+	limits.SetField("MaxConns", 10)
+	limits.Save()
+
+	if cfg.Network.Host != "" || len(cfg.Peers) != 1 || cfg.Limits != nil {
+		t.Errorf("expected no changes before saving, got %+v", cfg)
+	}
+
+	form.Save()
+
+	if cfg.Network.Host != "example.com" || len(cfg.Peers) != 0 || cfg.Limits == nil || cfg.Limits.MaxConns != 10 {
+		t.Errorf("got %+v", cfg)
+	}
+}
@@ -0,0 +1,12 @@
+package autoconfig
+
+import (
+	"github.com/mappu/autoconfig/schema"
+)
+
+// ChangeSet lists the changes that were saved from a form, e.g. to restart
+// only the affected parts of an application. See schema.ChangeSet.
+type ChangeSet = schema.ChangeSet
+
+// Change describes a single change in a ChangeSet. See schema.Change.
+type Change = schema.Change
@@ -0,0 +1,73 @@
+// Command autoconfig-edit edits a JSON document in a dialog, using a JSON
+// Schema to build the form.
+//
+// Usage:
+//
+//	autoconfig-edit SCHEMA.json DOCUMENT.json
+//
+// The document is created if it does not exist, and is written back when the
+// dialog is closed.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mappu/autoconfig"
+	qt "github.com/mappu/miqt/qt6"
+)
+
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Fprintln(os.Stderr, "Usage: autoconfig-edit SCHEMA.json DOCUMENT.json")
+		os.Exit(1)
+	}
+
+	schemaPath, docPath := os.Args[1], os.Args[2]
+
+	var jsonSchema map[string]any
+	if err := readJSON(schemaPath, &jsonSchema); err != nil {
+		fail(err)
+	}
+
+	doc := make(map[string]any)
+	if err := readJSON(docPath, &doc); err != nil && !os.IsNotExist(err) {
+		fail(err)
+	}
+
+	qt.NewQApplication(os.Args)
+
+	err := autoconfig.OpenSchemaDialog(jsonSchema, &doc, nil, filepath.Base(docPath), func() {
+		data, err := json.MarshalIndent(doc, "", "\t")
+		if err == nil {
+			err = os.WriteFile(docPath, append(data, '\n'), 0644)
+		}
+		if err != nil {
+			fail(err)
+		}
+	})
+	if err != nil {
+		fail(err)
+	}
+
+	qt.QApplication_Exec()
+}
+
+func readJSON(path string, dest any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "autoconfig-edit:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,81 @@
+package autoconfig
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// Codec serializes a configuration struct to and from a file format. It is
+// used by the "Import..." and "Export..." buttons added with WithImportExport.
+type Codec interface {
+	// Name is the human-readable name of the format (e.g. "JSON"). It is
+	// shown in the file dialog's filter list.
+	Name() string
+
+	// Extensions is the list of file extensions for this format, without a
+	// leading dot (e.g. "json").
+	Extensions() []string
+
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec serializes configuration structs using the standard library
+// encoding/json package.
+type JSONCodec struct{}
+
+func (JSONCodec) Name() string {
+	return "JSON"
+}
+
+func (JSONCodec) Extensions() []string {
+	return []string{"json"}
+}
+
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.MarshalIndent(v, "", "\t")
+}
+
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// codecFilter builds a Qt file dialog filter string for the supplied codecs.
+func codecFilter(codecs []Codec) string {
+	var filters []string
+	for _, c := range codecs {
+		var patterns []string
+		for _, ext := range c.Extensions() {
+			patterns = append(patterns, "*."+ext)
+		}
+		filters = append(filters, c.Name()+" ("+strings.Join(patterns, " ")+")")
+	}
+
+	return strings.Join(filters, ";;") // Same separator as Qt filter (yfilter)
+}
+
+// codecForPath picks a codec based on the file extension. If no codec matches,
+// the first codec is used.
+func codecForPath(codecs []Codec, path string) Codec {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	for _, c := range codecs {
+		for _, cext := range c.Extensions() {
+			if strings.ToLower(cext) == ext {
+				return c
+			}
+		}
+	}
+
+	return codecs[0]
+}
+
+// codecTarget gets a pointer to the value, suitable for passing to a Codec.
+func codecTarget(rv *reflect.Value) any {
+	if rv.Kind() == reflect.Pointer {
+		return rv.Interface()
+	}
+
+	return rv.Addr().Interface()
+}
@@ -0,0 +1,252 @@
+package autoconfig
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mappu/autoconfig/schema"
+)
+
+// INICodec serializes configuration structs in a TOML-like INI format.
+//
+// Child structs are written as [Section] headers, using dotted paths for
+// deeper nesting (e.g. [Network.Proxy]). All other fields are written as
+// `key = value` pairs, where the value is encoded as JSON.
+// Field names follow the `json` struct tag, if present.
+type INICodec struct{}
+
+func (INICodec) Name() string {
+	return "INI"
+}
+
+func (INICodec) Extensions() []string {
+	return []string{"ini", "conf"}
+}
+
+type iniField struct {
+	Name  string
+	Value reflect.Value
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// iniFields lists all the serializable fields of a struct. Embedded structs
+// are flattened in the same way as encoding/json.
+// If alloc is set, nil embedded pointers are allocated, otherwise they are
+// skipped.
+func iniFields(rv reflect.Value, alloc bool) []iniField {
+	var ret []iniField
+
+	obj := rv.Type()
+	nf := obj.NumField()
+	for i := 0; i < nf; i++ {
+		ff := obj.Field(i)
+		fv := rv.Field(i)
+
+		if _, hasJsonTag := ff.Tag.Lookup("json"); ff.Anonymous && !hasJsonTag {
+			// Embedded struct, flatten its fields into ours
+			if fv.Kind() == reflect.Pointer && fv.Type().Elem().Kind() == reflect.Struct {
+				if fv.IsNil() {
+					if !alloc || !fv.CanSet() {
+						continue
+					}
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+
+			if fv.Kind() == reflect.Struct {
+				ret = append(ret, iniFields(fv, alloc)...)
+				continue
+			}
+		}
+
+		name, ok := schema.JSONName(ff)
+		if !ok {
+			continue
+		}
+
+		ret = append(ret, iniField{name, fv})
+	}
+
+	return ret
+}
+
+// iniIsSection checks if the type should be written as a [Section].
+func iniIsSection(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	// Types with custom serialization (e.g. time.Time) are written as values
+	pt := reflect.PointerTo(t)
+	return !pt.Implements(jsonMarshalerType) && !pt.Implements(textMarshalerType)
+}
+
+func (INICodec) Marshal(v any) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.New("INICodec: can only marshal struct types, got " + rv.Type().String())
+	}
+
+	var buf bytes.Buffer
+	err := iniMarshalSection(&buf, rv, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func iniMarshalSection(buf *bytes.Buffer, rv reflect.Value, section string) error {
+
+	// All keys must be written before any child section header
+	var children []iniField
+
+	for _, fld := range iniFields(rv, false) {
+		if iniIsSection(fld.Value.Type()) {
+			children = append(children, fld)
+			continue
+		}
+
+		enc, err := json.Marshal(fld.Value.Interface())
+		if err != nil {
+			return fmt.Errorf("%s: %w", iniJoin(section, fld.Name), err)
+		}
+
+		fmt.Fprintf(buf, "%s = %s\n", fld.Name, enc)
+	}
+
+	for _, child := range children {
+		fv := child.Value
+		for fv.Kind() == reflect.Pointer && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Pointer {
+			continue // Nil pointer, omit the whole section
+		}
+
+		childSection := iniJoin(section, child.Name)
+		fmt.Fprintf(buf, "\n[%s]\n", childSection)
+
+		err := iniMarshalSection(buf, fv, childSection)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func iniJoin(section, name string) string {
+	if section == "" {
+		return name
+	}
+	return section + "." + name
+}
+
+// iniLookup finds a field by name in the struct, allocating any nil pointers
+// so that the result is a settable struct field.
+// Names are matched case-insensitively if there is no exact match, in the same
+// way as encoding/json.
+func iniLookup(rv reflect.Value, name string) (reflect.Value, bool) {
+	fields := iniFields(rv, true)
+
+	for _, fld := range fields {
+		if fld.Name == name {
+			return fld.Value, true
+		}
+	}
+	for _, fld := range fields {
+		if strings.EqualFold(fld.Name, name) {
+			return fld.Value, true
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// iniDeref follows any pointers, allocating them if they are nil.
+func iniDeref(rv reflect.Value) reflect.Value {
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+func (INICodec) Unmarshal(data []byte, v any) error {
+	root := reflect.ValueOf(v)
+	if root.Kind() != reflect.Pointer || root.IsNil() {
+		return errors.New("INICodec: Unmarshal requires a non-nil pointer")
+	}
+
+	top := iniDeref(root.Elem())
+	if top.Kind() != reflect.Struct {
+		return errors.New("INICodec: can only unmarshal struct types, got " + top.Type().String())
+	}
+
+	section := top
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue // Blank or comment
+		}
+
+		if line[0] == '[' {
+			if !strings.HasSuffix(line, "]") {
+				return fmt.Errorf("INICodec: line %d: unterminated section header", i+1)
+			}
+
+			// Walk from the top for every section header
+			section = top
+			for _, part := range strings.Split(line[1:len(line)-1], ".") {
+				fv, ok := iniLookup(section, strings.TrimSpace(part))
+				if !ok || !iniIsSection(fv.Type()) {
+					return fmt.Errorf("INICodec: line %d: unknown section %q", i+1, line)
+				}
+				section = iniDeref(fv)
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("INICodec: line %d: expected key = value", i+1)
+		}
+
+		fv, ok := iniLookup(section, strings.TrimSpace(key))
+		if !ok {
+			continue // Unknown keys are ignored, in the same way as encoding/json
+		}
+
+		err := json.Unmarshal([]byte(strings.TrimSpace(value)), fv.Addr().Interface())
+		if err != nil {
+			return fmt.Errorf("INICodec: line %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package autoconfig
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testCodecInner struct {
+	Enabled bool
+	Name    string `json:"name"`
+}
+
+type testCodecEmbedded struct {
+	Embedded string
+}
+
+type testCodecStruct struct {
+	Title    string
+	Count    int    `json:"count"`
+	Skipped  string `json:"-"`
+	When     time.Time
+	Tags     []string
+	Limits   map[string]int
+	Address  AddressPort
+	Inner    testCodecInner
+	InnerPtr *testCodecInner
+	NilPtr   *testCodecInner
+	testCodecEmbedded
+}
+
+func TestINICodec(t *testing.T) {
+
+	input := testCodecStruct{
+		Title:    "Hello \"world\"",
+		Count:    42,
+		Skipped:  "not serialized",
+		When:     time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Tags:     []string{"a", "b"},
+		Limits:   map[string]int{"x": 1},
+		Address:  AddressPort{"localhost", 8080},
+		Inner:    testCodecInner{Enabled: true, Name: "inner"},
+		InnerPtr: &testCodecInner{Name: "pointer"},
+		testCodecEmbedded: testCodecEmbedded{
+			Embedded: "flattened",
+		},
+	}
+
+	data, err := INICodec{}.Marshal(&input)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var got testCodecStruct
+	err = INICodec{}.Unmarshal(data, &got)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v\n%s", err, string(data))
+	}
+
+	want := input
+	want.Skipped = ""
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round-trip mismatch\ngot:  %#v\nwant: %#v\n%s", got, want, string(data))
+	}
+}
+
+func TestINICodecUnmarshal(t *testing.T) {
+
+	input := `
+; comment
+count = 5
+
+[inner]
+NAME = "case insensitive"
+
+[InnerPtr]
+enabled = true
+`
+
+	var got testCodecStruct
+	err := INICodec{}.Unmarshal([]byte(input), &got)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if got.Count != 5 {
+		t.Errorf("Count: got %d, want 5", got.Count)
+	}
+	if got.Inner.Name != "case insensitive" {
+		t.Errorf("Inner.Name: got %q", got.Inner.Name)
+	}
+	if got.InnerPtr == nil || !got.InnerPtr.Enabled {
+		t.Errorf("InnerPtr: got %#v", got.InnerPtr)
+	}
+
+	err = INICodec{}.Unmarshal([]byte("[Missing]\n"), &got)
+	if err == nil {
+		t.Errorf("expected error for unknown section")
+	}
+}
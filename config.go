@@ -2,8 +2,8 @@ package autoconfig
 
 import (
 	"reflect"
-	"time"
 
+	"github.com/mappu/autoconfig/schema"
 	qt "github.com/mappu/miqt/qt6"
 )
 
@@ -19,6 +19,103 @@ type Resetter interface {
 
 type SaveFunc func()
 
+// Option customizes the behaviour of OpenDialog and MakeConfigArea.
+type Option func(*options)
+
+type options struct {
+	codecs     []Codec
+	envPrefix  *string
+	filter     bool
+	treeLayout bool
+	readOnly   bool
+	confirm    bool
+	recent     *recentValues
+
+	hideJSONIgnored bool
+}
+
+func makeOptions(opts []Option) options {
+	var ret options
+	for _, opt := range opts {
+		opt(&ret)
+	}
+	return ret
+}
+
+// WithHideJSONIgnored hides fields with a `json:"-"` tag from the form, in the
+// same way as the `yhide` tag.
+func WithHideJSONIgnored() Option {
+	return func(o *options) {
+		o.hideJSONIgnored = true
+	}
+}
+
+// layout gets the options for schema.FormFields.
+func (o *options) layout() []schema.LayoutOption {
+	if o.hideJSONIgnored {
+		return []schema.LayoutOption{schema.HideJSONIgnored()}
+	}
+	return nil
+}
+
+// WithImportExport adds "Import..." and "Export..." buttons to the dialog, to
+// load and save the whole configuration from a file.
+// If no codecs are supplied, JSONCodec and INICodec are used.
+func WithImportExport(codecs ...Codec) Option {
+	if len(codecs) == 0 {
+		codecs = []Codec{JSONCodec{}, INICodec{}}
+	}
+
+	return func(o *options) {
+		o.codecs = codecs
+	}
+}
+
+// WithEnvOverrides shows fields that are overridden by environment variables
+// as read-only, with an explanation. The prefix should match the one passed
+// to LoadFromEnv.
+func WithEnvOverrides(prefix string) Option {
+	return func(o *options) {
+		o.envPrefix = &prefix
+	}
+}
+
+// WithFilter adds a search box to the top of the dialog, to show only the
+// fields that match. For MakeEditor, use Editor.SetFilter instead.
+func WithFilter() Option {
+	return func(o *options) {
+		o.filter = true
+	}
+}
+
+// WithTreeLayout shows nested values in a single dialog, with a tree sidebar
+// and a breadcrumb bar, instead of opening a new dialog for each level.
+// The `ylayout:"tree"` tag does the same for the dialogs opened from a field.
+func WithTreeLayout() Option {
+	return func(o *options) {
+		o.treeLayout = true
+	}
+}
+
+// WithReadOnly shows every field as non-editable. Nested dialogs can still be
+// opened to view their values, but no changes are saved.
+func WithReadOnly() Option {
+	return func(o *options) {
+		o.readOnly = true
+	}
+}
+
+// WithConfirmChanges shows a summary of the changes when "OK" is clicked, with
+// the old and new values. The user can go back to editing, or confirm them.
+// The dialog edits a copy of the struct, which is only applied after the
+// changes are confirmed. The dialog also has a "Cancel" button, to discard the
+// changes; onFinished is called either way.
+func WithConfirmChanges() Option {
+	return func(o *options) {
+		o.confirm = true
+	}
+}
+
 // Renderer is a custom-rendered type that can be interacted with
 // automatically by the autoconfig package.
 type Renderer interface {
@@ -26,12 +123,18 @@ type Renderer interface {
 }
 
 // MakeConfigArea makes a config area by pushing elements into a QFormLayout.
-// Use the returned function to force all changes from the UI to be saved to
-// the struct.
-func MakeConfigArea(ct ConfigurableStruct, area *qt.QFormLayout) SaveFunc {
+// The form edits a copy of the struct, so the struct is only changed when the
+// returned function is called, which saves all changes from the UI to it. Use
+// MakeEditor instead for access to the undo history.
+// Options that only apply to dialogs (e.g. WithImportExport) are ignored.
+func MakeConfigArea(ct ConfigurableStruct, area *qt.QFormLayout, opts ...Option) SaveFunc {
+	return MakeEditor(ct, area, opts...).Save
+}
 
-	rv := reflect.ValueOf(ct)
-	return makeConfigAreaFor(&rv, area, reflect.StructTag(""), defaultLabel)
+// MakeConfigAreaWithChanges makes a config area in the same way as
+// MakeConfigArea. The returned function saves the changes, and describes them.
+func MakeConfigAreaWithChanges(ct ConfigurableStruct, area *qt.QFormLayout, opts ...Option) func() ChangeSet {
+	return MakeEditor(ct, area, opts...).SaveChanges
 }
 
 func makeConfigAreaFor(rv *reflect.Value, area *qt.QFormLayout, tag reflect.StructTag, label string) SaveFunc {
@@ -72,65 +175,58 @@ func handle_any(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag,
 	} else if renderer, ok := rv.Addr().Interface().(Renderer); ok {
 		// The Renderer interface implemented with a Pointer receiver and we have a value
 		return renderer.Render(area, rv, tag, label)
+	}
 
-	} else if rv.Type() == reflect.TypeOf(time.Time{}) {
-		return handle_stdlibTimeTime(area, rv, tag, label) // Handle this case earlier, otherwise, it would match Struct
-
-	} else if rv.Type() == reflect.TypeOf(time.Duration(0)) {
-		return handle_stdlibTimeDuration(area, rv, tag, label) // Handle this case earlier, otherwise, it would match Int64
+	switch schema.Classify(rv.Type(), tag) {
+	case schema.KindTime:
+		return handle_stdlibTimeTime(area, rv, tag, label)
 
-	} else if rv.Type() == reflect.TypeOf([]byte{}) {
-		return handle_byte_slice(area, rv, tag, label) // Handle this case earler, otherwise, it would match slice
+	case schema.KindDuration:
+		return handle_stdlibTimeDuration(area, rv, tag, label)
 
-	} else {
-		switch rv.Type().Kind() {
-		case reflect.Func, reflect.UnsafePointer, reflect.Chan:
-			// No way we can configure these types
-			return handle_fixed(area, rv, tag, label)
+	case schema.KindBytes:
+		return handle_byte_slice(area, rv, tag, label)
 
-		case reflect.Bool:
-			return handle_bool(area, rv, tag, label)
+	case schema.KindFixed:
+		// No way we can configure these types (func, chan, unsafe pointer)
+		// If it's an interface (error, io.Reader, io.Writer, ...) then skip it
+		return handle_fixed(area, rv, tag, label)
 
-		case reflect.String:
-			return handle_string(area, rv, tag, label)
+	case schema.KindBool:
+		return handle_bool(area, rv, tag, label)
 
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			return handle_int(area, rv, tag, label)
+	case schema.KindString:
+		return handle_string(area, rv, tag, label)
 
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-			return handle_uint(area, rv, tag, label)
+	case schema.KindInt:
+		return handle_int(area, rv, tag, label)
 
-		case reflect.Float32, reflect.Float64:
-			return handle_float(area, rv, tag, label)
+	case schema.KindUint:
+		return handle_uint(area, rv, tag, label)
 
-		case reflect.Complex64, reflect.Complex128:
-			return handle_complex(area, rv, tag, label)
+	case schema.KindFlags:
+		return handle_flags(area, rv, tag, label)
 
-		case reflect.Struct:
-			// Struct by non-pointer
-			// Integrate it directly
-			return handle_struct(area, rv, tag, label)
+	case schema.KindFloat:
+		return handle_float(area, rv, tag, label)
 
-		case reflect.Slice, reflect.Array:
-			return handle_slice_or_array(area, rv, tag, label)
+	case schema.KindComplex:
+		return handle_complex(area, rv, tag, label)
 
-		case reflect.Pointer:
-			return handle_pointer(area, rv, tag, label)
+	case schema.KindStruct, schema.KindOneOf, schema.KindTabGroup, schema.KindGroupBox:
+		// Struct by non-pointer
+		// Integrate it directly
+		return handle_struct(area, rv, tag, label)
 
-		case reflect.Interface:
-			// If it's an interface (error, io.Reader, io.Writer, ...) then skip it
-			return handle_fixed(area, rv, tag, label)
+	case schema.KindSlice, schema.KindArray:
+		return handle_slice_or_array(area, rv, tag, label)
 
-		case reflect.Map:
-			return handle_map(area, rv, tag, label)
+	case schema.KindMap:
+		return handle_map(area, rv, tag, label)
 
-		default:
-			// The above enum should have covered every constant Kind available
-			// in the stdlib reflect package
-			// If there's something new in here, either data is corrupt or
-			// a future version of Go has added something fundamentally new
-			panic("makeConfigArea missing handling for type=" + rv.Type().String())
-		}
+	default:
+		// Every other kind has a Renderer, so it was handled above
+		panic("makeConfigArea missing handling for type=" + rv.Type().String())
 	}
 
 }
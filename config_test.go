@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"reflect"
 	"testing"
 	"time"
 
@@ -233,3 +234,16 @@ func TestAutoConfig(t *testing.T) {
 	qt.QApplication_Exec()
 
 }
+
+func TestStagedCopy(t *testing.T) {
+	cfg := TestInnerStruct{}
+	rv := reflect.ValueOf(&cfg)
+
+	staged := stagedCopy(&rv, func() { cfg.Bar = true })
+	if cfg.Bar {
+		t.Errorf("expected the value to be restored after staging")
+	}
+	if !staged.Interface().(TestInnerStruct).Bar {
+		t.Errorf("expected the copy to include the staged change")
+	}
+}
@@ -0,0 +1,102 @@
+package autoconfig
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/mappu/autoconfig/schema"
+	qt "github.com/mappu/miqt/qt6"
+	"github.com/mappu/miqt/qt6/mainthread"
+)
+
+// DefaultProvider is implemented by configurable structs that compute the
+// default values of their fields. See schema.DefaultProvider for details.
+type DefaultProvider = schema.DefaultProvider
+
+// defaultMarker tracks whether a field's value differs from its default.
+type defaultMarker struct {
+	value  reflect.Value
+	def    reflect.Value
+	action *qt.QAction // "Reset to default", only enabled if the value differs
+	label  *qt.QWidget // Shown in bold if the value differs, or nil
+}
+
+// addResetAction adds a "Reset to default" action to the context menu of a
+// field's rows. If the field has a default from schema.Default, its label is
+// also shown in bold while the value differs from it. Otherwise, the field is
+// reset to its zero value.
+func (f *formContext) addResetAction(area *qt.QFormLayout, firstRow, endRow int, parent *reflect.Value, field *schema.Field, value reflect.Value) {
+	if f.editor == nil || f.readOnly || firstRow == endRow || !value.CanSet() {
+		return // The form can't be rebuilt, or the value can't be changed
+	}
+	if _, ok := field.Tag.Lookup("yreadonly"); ok {
+		return
+	}
+	if _, ok := f.envOverride(value); ok {
+		return
+	}
+
+	def, explicit := schema.Default(*parent, field)
+	if !explicit {
+		def = reflect.Zero(field.Type)
+	}
+
+	// Text inputs keep their own context menu
+	label := filterLabel(area, firstRow)
+	var targets []*qt.QWidget
+	if label != nil {
+		targets = append(targets, label)
+	}
+	for _, w := range rowWidgets(area, firstRow, endRow) {
+		if (label != nil && w.UnsafePointer() == label.UnsafePointer()) || w.Inherits("QLineEdit") || w.Inherits("QAbstractSpinBox") || w.Inherits("QTextEdit") {
+			continue
+		}
+		targets = append(targets, w)
+	}
+	if len(targets) == 0 {
+		return
+	}
+
+	editor := f.editor
+	action := qt.NewQAction5(tr("Reset to default"), targets[0].QObject)
+	action.OnTriggered(func() {
+		// Resetting rebuilds the form, so don't do it while the menu is still in use
+		mainthread.Start(func() { editor.reset(value, def, field.Label) })
+	})
+	for _, w := range targets {
+		w.SetContextMenuPolicy(qt.ActionsContextMenu)
+		w.AddAction(action)
+	}
+
+	marker := &defaultMarker{value: value, def: def, action: action}
+	if explicit && label != nil {
+		marker.label = label
+		if !field.Secret && label.ToolTip() == "" {
+			label.SetToolTip(fmt.Sprintf(tr("Default: %s"), formatValue(&def, field.Tag)))
+		}
+	}
+	editor.defaults = append(editor.defaults, marker)
+}
+
+// refreshDefaults updates the markers for fields that differ from their
+// default. The form must be saved first.
+func (e *Editor) refreshDefaults() {
+	for _, m := range e.defaults {
+		changed := !reflect.DeepEqual(m.value.Interface(), m.def.Interface())
+		m.action.SetEnabled(changed)
+
+		if m.label != nil {
+			font := m.label.Font()
+			font.SetBold(changed)
+			m.label.SetFont(font)
+		}
+	}
+}
+
+// reset sets a value to its default, and rebuilds the form to show it.
+func (e *Editor) reset(value, def reflect.Value, label string) {
+	e.saver() // Keep any other changes that were not recorded yet
+	schema.Assign(value, def)
+	e.rebuild()
+	e.record(undoText("Reset", label))
+}
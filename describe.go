@@ -0,0 +1,22 @@
+package autoconfig
+
+import (
+	"reflect"
+
+	"github.com/mappu/autoconfig/schema"
+)
+
+// Describe builds a toolkit-neutral description of the configurable struct,
+// using the same rules as the Qt renderer. See the schema package for details.
+// Options that don't change which fields are shown (e.g. WithFilter) are
+// ignored.
+func Describe(ct ConfigurableStruct, opts ...Option) *schema.Schema {
+	o := makeOptions(opts)
+	return schema.Describe(ct, o.layout()...)
+}
+
+// JSONSchema builds a JSON Schema (draft 2020-12) for the JSON encoding of a
+// configurable struct type. See schema.JSONSchema for details.
+func JSONSchema(t reflect.Type) map[string]any {
+	return schema.JSONSchema(t)
+}
@@ -0,0 +1,152 @@
+package autoconfig
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mappu/autoconfig/schema"
+)
+
+type testDescribeStruct struct {
+	Cache     Bytes
+	Mode      EnumList `yenum:"Fast;;Slow"`
+	Address   AddressPort
+	Secret    *Password
+	Plain     Factor
+	Transport struct {
+		Mode OneOf
+		TCP  *AddressPort `ylabel:"Over TCP"`
+		Unix *string
+	}
+	Fallback *testDescribeTransport
+	Tabs     struct {
+		TabGroup
+		General struct{ Name string }
+	}
+	Advanced struct {
+		GroupBox
+		Debug   bool   `ygroup:"Logging" ycollapsed:"true"`
+		LogFile string `ygroup:"Logging"`
+	}
+}
+
+type testDescribeTransport struct {
+	Mode OneOf
+	TCP  *AddressPort
+	Unix *string
+}
+
+func TestDescribe(t *testing.T) {
+	s := Describe(&testDescribeStruct{})
+
+	type testCase struct {
+		path string
+		kind schema.Kind
+	}
+
+	cases := []testCase{
+		{"Cache", schema.KindFactor},
+		{"Mode", schema.KindEnumList},
+		{"Address", schema.KindAddressPort},
+		{"Secret", schema.KindPointer},
+		{"Plain", schema.KindInt}, // No yfactor tag
+		{"Transport", schema.KindOneOf},
+		{"Transport.TCP", schema.KindPointer},
+		{"Tabs", schema.KindTabGroup},
+		{"Tabs.General.Name", schema.KindString},
+		{"Advanced", schema.KindGroupBox},
+		{"Advanced.Debug", schema.KindBool},
+	}
+
+	for _, tc := range cases {
+		f := s.Lookup(tc.path)
+		if f == nil {
+			t.Errorf("Lookup(%q): not found", tc.path)
+			continue
+		}
+		if f.Kind != tc.kind {
+			t.Errorf("Lookup(%q): got %v, want %v", tc.path, f.Kind, tc.kind)
+		}
+	}
+
+	if f := s.Lookup("Mode"); len(f.EnumOptions) != 2 || f.EnumOptions[1] != "Slow" {
+		t.Errorf("Mode: got options %v", f.EnumOptions)
+	}
+
+	if f := s.Lookup("Secret"); !f.Secret || f.Elem.Kind != schema.KindPassword {
+		t.Errorf("Secret: got %#v", f)
+	}
+
+	// The OneOf selector is not a child, only the options
+	if f := s.Lookup("Transport"); len(f.Children) != 2 || f.Children[0].Label != "Over TCP" {
+		t.Errorf("Transport: got %d children", len(f.Children))
+	}
+
+	// The GroupBox marker is not a child either
+	if f := s.Lookup("Advanced"); len(f.Children) != 2 || f.Children[1].Group != "Logging" {
+		t.Errorf("Advanced: got %d children", len(f.Children))
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	s := JSONSchema(reflect.TypeOf(testDescribeStruct{}))
+	props := s["properties"].(map[string]any)
+
+	mode := props["Mode"].(map[string]any)
+	if options := mode["oneOf"].([]any); mode["type"] != "integer" || len(options) != 2 {
+		t.Errorf("Mode: got %v", mode)
+	}
+
+	transport := props["Transport"].(map[string]any)
+	if options := transport["oneOf"].([]any); len(options) != 3 {
+		t.Errorf("Transport: got %v", transport)
+	}
+	selector := transport["properties"].(map[string]any)["Mode"].(map[string]any)
+	if !reflect.DeepEqual(selector["enum"], []string{"", "TCP", "Unix"}) {
+		t.Errorf("Transport.Mode: got %v", selector)
+	}
+
+	// A nullable OneOf must not add null to the type, as null would then
+	// match every option
+	fallback := props["Fallback"].(map[string]any)
+	if anyOf, ok := fallback["anyOf"].([]any); !ok || len(anyOf) != 2 {
+		t.Errorf("Fallback: got %v", fallback)
+	} else if inner := anyOf[0].(map[string]any); inner["type"] != "object" || inner["oneOf"] == nil {
+		t.Errorf("Fallback: got %v", inner)
+	}
+
+	if secret := props["Secret"].(map[string]any); secret["writeOnly"] != true {
+		t.Errorf("Secret: got %v", secret)
+	}
+}
+
+type testParseEnumStruct struct {
+	Profiles []string
+	Active   EnumString `yenum:"test-parse-profiles"`
+}
+
+func (s *testParseEnumStruct) EnumOptions(field string) ([]string, bool) {
+	if field == "Active" {
+		return s.Profiles, true
+	}
+	return nil, false
+}
+
+func TestParseTextFor(t *testing.T) {
+	cfg := testParseEnumStruct{Profiles: []string{"home", "work"}}
+	owner := reflect.ValueOf(&cfg).Elem()
+	ff, _ := owner.Type().FieldByName("Active")
+	rv := owner.FieldByName("Active")
+
+	if err := schema.ParseTextFor(owner, "Active", rv, ff.Tag, "WORK"); err != nil || cfg.Active != "work" {
+		t.Errorf("EnumProvider: got %q, %v", cfg.Active, err)
+	}
+	if err := schema.ParseTextFor(owner, "Active", rv, ff.Tag, "office"); err == nil {
+		t.Errorf("EnumProvider: expected error for unknown option")
+	}
+
+	// Without the owner, the key is not registered, so any value is accepted
+	if err := schema.ParseText(rv, ff.Tag, "office"); err != nil || cfg.Active != "office" {
+		t.Errorf("ParseText: got %q, %v", cfg.Active, err)
+	}
+}
@@ -1,21 +1,55 @@
 package autoconfig
 
 import (
+	"os"
 	"reflect"
 
+	"github.com/mappu/autoconfig/schema"
 	qt "github.com/mappu/miqt/qt6"
 )
 
 // OpenDialog opens the struct for editing in a new modal dialog in the current
 // global event loop.
-// The dialog only has an "OK" button, you can't cancel your modifications to
-// the supplied struct, the struct saver is always called.
-func OpenDialog(ct ConfigurableStruct, parent *qt.QWidget, title string, onFinished func()) {
+// By default, the dialog only has an "OK" button, and closing it in any way
+// saves the changes into the supplied struct. With WithConfirmChanges, there is
+// also a "Cancel" button, and the changes are only saved once confirmed.
+// The onFinished callback is called in either case.
+func OpenDialog(ct ConfigurableStruct, parent *qt.QWidget, title string, onFinished func(), opts ...Option) {
 	rv := reflect.ValueOf(ct)
-	openDialogFor(&rv, parent, reflect.StructTag(""), title, onFinished)
+	openDialogFor(&rv, parent, reflect.StructTag(""), title, onFinished, newFormContext(&rv, opts))
 }
 
-func openDialogFor(rv *reflect.Value, parent *qt.QWidget, tag reflect.StructTag, title string, onFinished func()) {
+// OpenDialogWithChanges opens the struct for editing in the same way as
+// OpenDialog, and passes the changes that were saved to onFinished.
+func OpenDialogWithChanges(ct ConfigurableStruct, parent *qt.QWidget, title string, onFinished func(ChangeSet), opts ...Option) {
+	rv := reflect.ValueOf(ct)
+	before := schema.Clone(rv)
+	openDialogFor(&rv, parent, reflect.StructTag(""), title, func() {
+		onFinished(schema.Diff(before, rv))
+	}, newFormContext(&rv, opts))
+}
+
+func openDialogFor(rv *reflect.Value, parent *qt.QWidget, tag reflect.StructTag, title string, onFinished func(), form *formContext) {
+	title = schema.StripMnemonic(title)
+
+	if form.tree != nil {
+		// Inside a tree layout dialog, show it as a new page instead
+		form.tree.push(rv, tag, title, onFinished, form, true)
+		return
+	}
+
+	var review *changeReview
+	if form.confirm {
+		// Edit a copy, which is applied after the review
+		review = newChangeReview(rv)
+		form.followEnvOverrides(*rv, *review.working) // Keep the overridden fields read-only
+		rv = review.working
+	}
+
+	if form.treeLayout || tag.Get("ylayout") == "tree" {
+		openTreeDialogFor(rv, parent, tag, title, onFinished, form, review)
+		return
+	}
 
 	dlg := qt.NewQDialog(parent)
 	dlg.SetModal(true)
@@ -30,40 +64,47 @@ func openDialogFor(rv *reflect.Value, parent *qt.QWidget, tag reflect.StructTag,
 	//       - FormLayout    <-- attach to config
 	//   - QStandardButtonBar
 
-	formArea := qt.NewQFormLayout2()
-	formArea.SetContentsMargins(0, 0, 0, 0)
-	formArea.SetSpacing(6)
-	formArea.SetSizeConstraint(qt.QLayout__SetMinAndMaxSize)
+	formArea := newFormArea()
 	// Pass through a blank label. The main label is in the dialog header instead.
-	applyer := makeConfigAreaFor(rv, formArea, tag, "")
-
-	viewport := qt.NewQWidget(dlg.QWidget)
-	viewport.SetLayout(formArea.QLayout)
+	editor := newEditor(rv, formArea, tag, "", form)
+	addUndoShortcuts(dlg.QWidget, func() *Editor { return editor })
 
-	scrollArea := qt.NewQScrollArea2()
-	scrollArea.SetFrameShape(qt.QFrame__NoFrame)
-	scrollArea.SetWidgetResizable(true)
-	szp := scrollArea.VerticalScrollBar().SizePolicy()
-	szp.SetRetainSizeWhenHidden(true)
-	scrollArea.VerticalScrollBar().SetSizePolicy(*szp)
-	scrollArea.SetWidget(viewport)
+	scrollArea := newScrollArea(dlg.QWidget, formArea)
 
 	vbox := qt.NewQVBoxLayout(dlg.QWidget)
 	vbox.SetContentsMargins(11, 11, 11, 11)
 	vbox.SetSpacing(40)
-	vbox.AddWidget(scrollArea.QWidget)
+	if form.filter {
+		// Keep the search box close to the form
+		inner := qt.NewQVBoxLayout2()
+		inner.SetSpacing(6)
+		addFilterBox(inner.QBoxLayout, editor.SetFilter)
+		inner.AddWidget(scrollArea.QWidget)
+		vbox.AddLayout(inner.QLayout)
+	} else {
+		vbox.AddWidget(scrollArea.QWidget)
+	}
 
 	buttons := qt.NewQDialogButtonBox(dlg.QWidget)
-	buttons.SetStandardButtons(qt.QDialogButtonBox__Ok)
-	buttons.OnAccepted(dlg.Accept)
+	addOkButton(dlg, buttons, review, editor.Save)
 	buttons.OnRejected(dlg.Reject)
 	vbox.AddWidget(buttons.QWidget)
 
+	if len(form.codecs) > 0 {
+		rebuild := func() {
+			editor.rebuild()
+			form.edited(tr("Import"))
+		}
+
+		addImportExportButtons(buttons, rv, form.codecs, form.readOnly, editor.Save, editor.Save, rebuild)
+	}
+
 	dlg.SetLayout(vbox.QLayout)
 
 	dlg.OnFinished(func(status int) {
 		// Save changes regardless of status
-		applyer()
+		editor.Save()
+		review.apply() // Unless the changes were not confirmed
 		onFinished()
 	})
 
@@ -80,3 +121,101 @@ func openDialogFor(rv *reflect.Value, parent *qt.QWidget, tag reflect.StructTag,
 
 	dlg.SetMinimumWidth(dlg.Width() + ESTIMATE_VSCROLLBAR_WIDTH)
 }
+
+// newFormArea creates the form layout for a dialog.
+func newFormArea() *qt.QFormLayout {
+	formArea := qt.NewQFormLayout2()
+	formArea.SetContentsMargins(0, 0, 0, 0)
+	formArea.SetSpacing(6)
+	formArea.SetSizeConstraint(qt.QLayout__SetMinAndMaxSize)
+	return formArea
+}
+
+// newScrollArea places the form layout in a scroll area.
+func newScrollArea(parent *qt.QWidget, formArea *qt.QFormLayout) *qt.QScrollArea {
+	viewport := qt.NewQWidget(parent)
+	viewport.SetLayout(formArea.QLayout)
+
+	scrollArea := qt.NewQScrollArea2()
+	scrollArea.SetFrameShape(qt.QFrame__NoFrame)
+	scrollArea.SetWidgetResizable(true)
+	szp := scrollArea.VerticalScrollBar().SizePolicy()
+	szp.SetRetainSizeWhenHidden(true)
+	scrollArea.VerticalScrollBar().SetSizePolicy(*szp)
+	scrollArea.SetWidget(viewport)
+	return scrollArea
+}
+
+// addImportExportButtons adds the "Import..." and "Export..." buttons to the
+// dialog's button bar.
+// The import is merged over any staged changes, so the form is saved first
+// with the save function, and then rebuilt to show the new values.
+// The export includes any staged changes, but doesn't change the value: the
+// stage function saves every open form, and the value is then restored.
+// A read-only form can only be exported.
+func addImportExportButtons(buttons *qt.QDialogButtonBox, rv *reflect.Value, codecs []Codec, readOnly bool, save func(), stage func(), rebuild func()) {
+
+	filter := codecFilter(codecs)
+
+	importBtn := buttons.AddButton2(tr("Import..."), qt.QDialogButtonBox__ActionRole)
+	importBtn.SetEnabled(!readOnly)
+	importBtn.OnClicked(func() {
+		filePath := qt.QFileDialog_GetOpenFileName4(importBtn.QWidget, tr("Import settings..."), "", filter)
+		if filePath == "" {
+			return // cancelled
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			qt.QMessageBox_Warning(importBtn.QWidget, tr("Error loading file content"), err.Error())
+			return
+		}
+
+		// Merge the file content over any staged changes
+		save()
+
+		err = codecForPath(codecs, filePath).Unmarshal(content, codecTarget(rv))
+		if err != nil {
+			qt.QMessageBox_Warning(importBtn.QWidget, tr("Error importing settings"), err.Error())
+			// The value may have been partially updated, so rebuild anyway
+		}
+
+		rebuild()
+	})
+
+	exportBtn := buttons.AddButton2(tr("Export..."), qt.QDialogButtonBox__ActionRole)
+	exportBtn.OnClicked(func() {
+		filePath := qt.QFileDialog_GetSaveFileName4(exportBtn.QWidget, tr("Export settings..."), "", filter)
+		if filePath == "" {
+			return // cancelled
+		}
+
+		staged := stagedCopy(rv, stage).Addr()
+		content, err := codecForPath(codecs, filePath).Marshal(codecTarget(&staged))
+		if err != nil {
+			qt.QMessageBox_Warning(exportBtn.QWidget, tr("Error exporting settings"), err.Error())
+			return
+		}
+
+		err = os.WriteFile(filePath, content, 0644)
+		if err != nil {
+			qt.QMessageBox_Warning(exportBtn.QWidget, tr("Error saving file content"), err.Error())
+			return
+		}
+
+		// Saved successfully
+	})
+}
+
+// stagedCopy gets a copy of the value with the staged changes from the form,
+// without changing the value itself.
+func stagedCopy(rv *reflect.Value, stage func()) reflect.Value {
+	root := valueRoot(*rv)
+	before := schema.Clone(root)
+
+	stage()
+	ret := schema.Clone(root)
+
+	schema.Assign(root, before)
+	return ret
+}
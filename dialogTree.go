@@ -0,0 +1,409 @@
+package autoconfig
+
+import (
+	"fmt"
+	"html"
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/mappu/autoconfig/schema"
+	qt "github.com/mappu/miqt/qt6"
+	"github.com/mappu/miqt/qt6/mainthread"
+)
+
+// maxTreeDepth limits the tree sidebar, in case of recursive types.
+const maxTreeDepth = 16
+
+// treeDialog is a dialog that shows nested values as pages, with a tree
+// sidebar and a breadcrumb bar, instead of opening a new dialog per level.
+type treeDialog struct {
+	dlg        *qt.QDialog
+	tree       *qt.QTreeWidget
+	stack      *qt.QStackedWidget
+	breadcrumb *qt.QLabel
+
+	pages  []*treePage                  // Open pages, starting from the root
+	nodes  map[unsafe.Pointer]*treeNode // By tree item
+	filter string
+	layout []schema.LayoutOption // For finding the fields of nested values
+}
+
+// treePage is an open page, equivalent to a nested dialog.
+type treePage struct {
+	title      string
+	editor     *Editor
+	widget     *qt.QWidget
+	onFinished func()
+}
+
+// treeNode is an entry in the tree sidebar, for a value that can be opened
+// as a page.
+type treeNode struct {
+	rv     reflect.Value // Addressable
+	tag    reflect.StructTag
+	title  string
+	key    string // Path of child indexes, to find the same node after a refresh
+	parent *treeNode
+	item   *qt.QTreeWidgetItem
+}
+
+func openTreeDialogFor(rv *reflect.Value, parent *qt.QWidget, tag reflect.StructTag, title string, onFinished func(), form *formContext, review *changeReview) {
+
+	t := &treeDialog{layout: form.layout()}
+	form.tree = t
+
+	t.dlg = qt.NewQDialog(parent)
+	t.dlg.SetModal(true)
+	t.dlg.SetWindowTitle(title)
+	t.dlg.SetAttribute(qt.WA_DeleteOnClose)
+	t.dlg.SetUpdatesEnabled(false) // Reduce flicker
+
+	// QDialog
+	// - VerticalLayout
+	//   - QLabel (breadcrumb)
+	//   - QSplitter
+	//     - QTreeWidget
+	//     - QStackedWidget
+	//       - QScrollArea per page
+	//   - QStandardButtonBar
+
+	t.breadcrumb = qt.NewQLabel2()
+	t.breadcrumb.SetTextFormat(qt.RichText)
+	t.breadcrumb.OnLinkActivated(func(link string) {
+		depth, err := strconv.Atoi(link)
+		if err != nil {
+			return
+		}
+		t.popTo(depth)
+		t.refresh()
+	})
+
+	t.tree = qt.NewQTreeWidget2()
+	t.tree.SetHeaderHidden(true)
+	t.tree.OnItemClicked(func(item *qt.QTreeWidgetItem, _ int) {
+		node, ok := t.nodes[item.UnsafePointer()]
+		if !ok {
+			return
+		}
+
+		// Opening a page rebuilds the tree, so don't do it while the clicked
+		// item is still in use
+		key := node.key
+		mainthread.Start(func() { t.open(key) })
+	})
+
+	t.stack = qt.NewQStackedWidget2()
+
+	splitter := qt.NewQSplitter3(qt.Horizontal)
+	splitter.AddWidget(t.tree.QWidget)
+	splitter.AddWidget(t.stack.QWidget)
+	splitter.SetStretchFactor(1, 1)
+	splitter.SetSizes([]int{200, 500})
+
+	vbox := qt.NewQVBoxLayout(t.dlg.QWidget)
+	vbox.SetContentsMargins(11, 11, 11, 11)
+	vbox.SetSpacing(6)
+	vbox.AddWidget(t.breadcrumb.QWidget)
+	if form.filter {
+		addFilterBox(vbox.QBoxLayout, func(text string) {
+			t.filter = text
+			t.top().editor.SetFilter(text)
+		})
+	}
+	vbox.AddWidget(splitter.QWidget)
+
+	buttons := qt.NewQDialogButtonBox(t.dlg.QWidget)
+	buttons.OnRejected(t.dlg.Reject)
+	vbox.AddWidget(buttons.QWidget)
+
+	t.push(rv, tag, title, nil, form, false)
+	root := t.pages[0]
+
+	addOkButton(t.dlg, buttons, review, func() {
+		t.popTo(0)
+		root.editor.Save()
+		t.refresh()
+	})
+
+	addUndoShortcuts(t.dlg.QWidget, func() *Editor { return t.top().editor })
+
+	if len(form.codecs) > 0 {
+		save := func() {
+			t.popTo(0)
+			root.editor.Save()
+		}
+		rebuild := func() {
+			root.editor.rebuild()
+			root.editor.record(tr("Import"))
+			t.refresh()
+		}
+
+		stage := func() {
+			// Keep the pages open, as nothing is committed
+			for i := len(t.pages) - 1; i >= 0; i-- {
+				t.pages[i].editor.Save()
+			}
+		}
+
+		addImportExportButtons(buttons, rv, form.codecs, form.readOnly, save, stage, rebuild)
+	}
+
+	t.dlg.SetLayout(vbox.QLayout)
+
+	t.dlg.OnFinished(func(status int) {
+		// Save changes regardless of status
+		t.popTo(0)
+		root.editor.Save()
+		review.apply() // Unless the changes were not confirmed
+		onFinished()
+	})
+
+	t.refresh()
+
+	t.dlg.SetUpdatesEnabled(true) // Reduce flicker
+
+	t.dlg.Resize(800, 600)
+	t.dlg.Show()
+}
+
+// top gets the page that is currently shown.
+func (t *treeDialog) top() *treePage {
+	return t.pages[len(t.pages)-1]
+}
+
+// push opens a new page on top of the current one. The onFinished function is
+// called when the page is closed, the same as for a nested dialog.
+func (t *treeDialog) push(rv *reflect.Value, tag reflect.StructTag, title string, onFinished func(), form *formContext, refresh bool) {
+	formArea := newFormArea()
+	// Pass through a blank label. The main label is in the breadcrumb instead.
+	editor := newEditor(rv, formArea, tag, "", form)
+	scrollArea := newScrollArea(t.dlg.QWidget, formArea)
+
+	t.pages = append(t.pages, &treePage{
+		title:      title,
+		editor:     editor,
+		widget:     scrollArea.QWidget,
+		onFinished: onFinished,
+	})
+
+	t.stack.AddWidget(scrollArea.QWidget)
+	t.stack.SetCurrentWidget(scrollArea.QWidget)
+
+	if t.filter != "" {
+		editor.SetFilter(t.filter)
+	}
+
+	if refresh {
+		t.refresh()
+	}
+}
+
+// popTo closes pages until the page at the given depth is shown. Each closed
+// page is saved, the same as closing a nested dialog.
+func (t *treeDialog) popTo(depth int) {
+	for len(t.pages) > depth+1 {
+		page := t.top()
+		t.pages = t.pages[:len(t.pages)-1]
+
+		page.editor.Save()
+		t.stack.RemoveWidget(page.widget)
+		page.widget.DeleteLater()
+
+		if page.onFinished != nil {
+			page.onFinished()
+		}
+	}
+
+	page := t.top()
+	t.stack.SetCurrentWidget(page.widget)
+	if page.editor.filterText != t.filter {
+		page.editor.SetFilter(t.filter)
+	}
+}
+
+// open shows the page for a tree node, reusing any pages that are already
+// open on the way to it.
+func (t *treeDialog) open(key string) {
+
+	// Closing pages may change the value (e.g. adding a slice item), so look
+	// up the node again afterwards
+	path := t.path(key)
+	if path == nil {
+		return
+	}
+	t.popTo(t.openDepth(path))
+	t.refresh()
+
+	path = t.path(key)
+	if path == nil {
+		return
+	}
+
+	for _, node := range path[t.openDepth(path)+1:] {
+		parent := t.top()
+		parent.editor.record("Edit") // Keep any changes on the parent page
+
+		rv := node.rv
+		title := node.title
+		t.push(&rv, node.tag, title, func() {
+			parent.editor.rebuild()
+			parent.editor.record(editText(title))
+		}, parent.editor.form.nested(), false)
+	}
+
+	t.refresh()
+}
+
+// path gets the nodes from the root to the node with the key.
+func (t *treeDialog) path(key string) []*treeNode {
+	for _, node := range t.nodes {
+		if node.key != key {
+			continue
+		}
+
+		var ret []*treeNode
+		for n := node; n != nil; n = n.parent {
+			ret = append([]*treeNode{n}, ret...)
+		}
+		return ret
+	}
+	return nil
+}
+
+// openDepth finds the deepest open page that is on the path.
+func (t *treeDialog) openDepth(path []*treeNode) int {
+	depth := 0
+	for depth+1 < len(t.pages) && depth+1 < len(path) && sameValue(t.pages[depth+1].editor.root(), path[depth+1].rv) {
+		depth++
+	}
+	return depth
+}
+
+// sameValue checks whether both values are the same variable.
+func sameValue(a, b reflect.Value) bool {
+	return a.CanAddr() && b.CanAddr() && a.Type() == b.Type() && a.UnsafeAddr() == b.UnsafeAddr()
+}
+
+// refresh rebuilds the tree sidebar and the breadcrumb bar from the current
+// value.
+func (t *treeDialog) refresh() {
+	t.tree.Clear()
+	t.nodes = make(map[unsafe.Pointer]*treeNode)
+
+	root := t.pages[0]
+	rootNode := t.addNode(nil, root.editor.root(), root.editor.tag, root.title)
+	t.addChildren(rootNode, rootNode.rv, rootNode.tag, 0)
+	t.tree.ExpandAll()
+
+	// Select the current page
+	current := t.top().editor.root()
+	for _, node := range t.nodes {
+		if sameValue(node.rv, current) {
+			t.tree.SetCurrentItem(node.item)
+		}
+	}
+
+	var crumbs []string
+	for i, page := range t.pages {
+		title := html.EscapeString(page.title)
+		if title == "" {
+			title = tr(defaultLabel)
+		}
+
+		if i == len(t.pages)-1 {
+			crumbs = append(crumbs, "<b>"+title+"</b>")
+		} else {
+			crumbs = append(crumbs, fmt.Sprintf(`<a href="%d">%s</a>`, i, title))
+		}
+	}
+	t.breadcrumb.SetText(strings.Join(crumbs, " › "))
+}
+
+func (t *treeDialog) addNode(parent *treeNode, rv reflect.Value, tag reflect.StructTag, title string) *treeNode {
+	node := &treeNode{
+		rv:     rv,
+		tag:    tag,
+		title:  title,
+		parent: parent,
+		item:   qt.NewQTreeWidgetItem2([]string{title}),
+	}
+
+	if parent == nil {
+		t.tree.AddTopLevelItem(node.item)
+	} else {
+		// Titles are not unique, e.g. for fields with the same label
+		node.key = parent.key + "/" + strconv.Itoa(parent.item.ChildCount())
+		parent.item.AddChild(node.item)
+	}
+
+	t.nodes[node.item.UnsafePointer()] = node
+	return node
+}
+
+// addChildren adds tree nodes for the parts of the value that have their own
+// page, i.e. nested structs and lists of structs.
+func (t *treeDialog) addChildren(parent *treeNode, rv reflect.Value, tag reflect.StructTag, depth int) {
+	if depth > maxTreeDepth {
+		return
+	}
+
+	switch schema.Classify(rv.Type(), tag) {
+	case schema.KindStruct, schema.KindTabGroup, schema.KindGroupBox:
+		for _, field := range schema.FormFields(rv.Type(), t.layout...) {
+			t.addField(parent, rv.FieldByIndex(field.IndexPath), field.Tag, field.Label, depth)
+		}
+
+	case schema.KindSlice, schema.KindArray:
+		for i := 0; i < rv.Len(); i++ {
+			if elem, ok := treeStruct(rv.Index(i), tag); ok {
+				node := t.addNode(parent, elem, tag, fmt.Sprintf("%s %d", parent.title, i+1))
+				t.addChildren(node, elem, tag, depth+1)
+			}
+		}
+	}
+}
+
+func (t *treeDialog) addField(parent *treeNode, rv reflect.Value, tag reflect.StructTag, label string, depth int) {
+	if elem, ok := treeStruct(rv, tag); ok {
+		node := t.addNode(parent, elem, tag, label)
+		t.addChildren(node, elem, tag, depth+1)
+		return
+	}
+
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	switch schema.Classify(rv.Type(), tag) {
+	case schema.KindSlice, schema.KindArray:
+		// Only lists of structs, otherwise the list is simple enough to edit
+		// on the parent page
+		elemType := rv.Type().Elem()
+		for elemType.Kind() == reflect.Pointer {
+			elemType = elemType.Elem()
+		}
+		if kind := schema.Classify(elemType, tag); kind == schema.KindStruct || kind == schema.KindTabGroup || kind == schema.KindGroupBox || kind == schema.KindOneOf {
+			node := t.addNode(parent, rv, tag, label)
+			t.addChildren(node, rv, tag, depth+1)
+		}
+	}
+}
+
+// treeStruct follows any pointers, and checks whether the value is a struct
+// that has its own page.
+func treeStruct(rv reflect.Value, tag reflect.StructTag) (reflect.Value, bool) {
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return rv, false
+		}
+		rv = rv.Elem()
+	}
+
+	switch schema.Classify(rv.Type(), tag) {
+	case schema.KindStruct, schema.KindTabGroup, schema.KindGroupBox, schema.KindOneOf:
+		return rv, true
+	}
+	return rv, false
+}
@@ -0,0 +1,287 @@
+package autoconfig
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/mappu/autoconfig/schema"
+	qt "github.com/mappu/miqt/qt6"
+)
+
+// Editor is a handle to a form that was created by MakeEditor or OpenDialog.
+// It keeps an undo history for every change made in the form.
+type Editor struct {
+	form     *formContext
+	rv       *reflect.Value // The value shown in the form
+	target   *reflect.Value // Where Save copies the value to, if it is a working copy
+	area     *qt.QFormLayout
+	tag      reflect.StructTag
+	label    string
+	firstRow int // Rows before this in the area belong to someone else
+	saver    SaveFunc
+
+	stack    *qt.QUndoStack
+	snapshot reflect.Value // Copy of the value, as of the top of the undo stack. Its parts are replaced, not changed
+	saved    reflect.Value // Copy of the value, as of the last SaveChanges
+	busy     bool          // Recording or restoring, ignore any further changes
+
+	filterRoot *filterNode
+	filterText string
+
+	defaults   []*defaultMarker // Fields that can be reset to their default
+	refreshers []func()         // Widgets that depend on other fields, see RefreshOptions
+}
+
+// MakeEditor makes a config area by pushing elements into a QFormLayout, the
+// same as MakeConfigArea, and returns a handle to it.
+// The form edits a copy of the struct, which is only saved into the struct by
+// Save or SaveChanges.
+func MakeEditor(ct ConfigurableStruct, area *qt.QFormLayout, opts ...Option) *Editor {
+	target := reflect.ValueOf(ct)
+	form := newFormContext(&target, opts)
+
+	work := schema.Clone(valueRoot(target))
+	form.followEnvOverrides(valueRoot(target), work)
+	rv := work
+	if target.Kind() == reflect.Pointer {
+		rv = work.Addr()
+	}
+
+	e := newEditor(&rv, area, reflect.StructTag(""), tr(defaultLabel), form)
+	e.target = &target
+	return e
+}
+
+func newEditor(rv *reflect.Value, area *qt.QFormLayout, tag reflect.StructTag, label string, form *formContext) *Editor {
+	e := &Editor{
+		form:     form,
+		rv:       rv,
+		area:     area,
+		tag:      tag,
+		label:    label,
+		firstRow: area.RowCount(),
+		stack:    qt.NewQUndoStack2(area.QObject),
+	}
+	form.editor = e
+
+	e.build()
+	e.snapshot = schema.Clone(e.root())
+	e.saved = reflect.New(e.snapshot.Type()).Elem()
+	e.saved.Set(e.snapshot) // The snapshot's parts are replaced, so they can be shared
+	return e
+}
+
+// root gets the value that is being edited, for taking and restoring copies.
+func (e *Editor) root() reflect.Value {
+	return valueRoot(*e.rv)
+}
+
+// valueRoot follows the pointer to a struct that was passed in by the caller,
+// to get a settable value.
+func valueRoot(rv reflect.Value) reflect.Value {
+	if !rv.CanAddr() && rv.Kind() == reflect.Pointer {
+		return rv.Elem()
+	}
+	return rv
+}
+
+func (e *Editor) build() {
+	e.defaults = nil
+	e.refreshers = nil
+	e.saver = e.form.build(func() SaveFunc {
+		var saver SaveFunc
+		e.form.fieldScope(e.area, "", func() {
+			e.filterRoot = e.form.filterScope(e.area, "", func() {
+				saver = makeConfigAreaFor(e.rv, e.area, e.tag, e.label)
+			})
+		})
+		return saver
+	})
+
+	if e.form.readOnly {
+		e.saver = func() {} // Nothing can be changed
+	}
+
+	if e.filterText != "" {
+		e.applyFilter()
+	}
+
+	e.refreshDefaults()
+}
+
+// rebuild replaces the form's widgets, to show the current value.
+func (e *Editor) rebuild() {
+	for e.area.RowCount() > e.firstRow {
+		e.area.RemoveRow(e.firstRow)
+	}
+	e.build()
+}
+
+// Save saves all changes from the UI into the struct.
+func (e *Editor) Save() {
+	e.saver()
+	if e.target != nil {
+		schema.Assign(valueRoot(*e.target), e.root())
+	}
+}
+
+// SaveChanges saves all changes from the UI into the struct, and describes the
+// changes since the editor was created, or since the last SaveChanges.
+func (e *Editor) SaveChanges() ChangeSet {
+	e.Save()
+
+	root := e.root()
+	ret := schema.Diff(e.saved, root)
+	e.saved = schema.Clone(root)
+	return ret
+}
+
+// RefreshOptions saves the form into its working copy, and computes the
+// options of EnumString fields again, e.g. after discovering devices. This
+// happens automatically after each edit in the form. Selected values are kept, even if they are no
+// longer one of the options.
+func (e *Editor) RefreshOptions() {
+	e.saver()
+	e.refreshOptions()
+}
+
+func (e *Editor) refreshOptions() {
+	for _, fn := range e.refreshers {
+		fn()
+	}
+}
+
+// UndoStack gets the undo history of the form, e.g. to show its state in a
+// toolbar. Use Undo and Redo to move through it, so that any edit still in
+// progress is recorded first.
+func (e *Editor) UndoStack() *qt.QUndoStack {
+	return e.stack
+}
+
+// Undo reverts the most recent change in the form.
+func (e *Editor) Undo() {
+	e.record(tr("Edit"))
+	e.stack.Undo()
+}
+
+// Redo reapplies the most recently undone change in the form.
+func (e *Editor) Redo() {
+	e.record(tr("Edit"))
+	e.stack.Redo()
+}
+
+// record pushes an undo step, if the value has changed since the last one.
+func (e *Editor) record(text string) {
+	if e.busy {
+		return
+	}
+	e.busy = true
+	defer func() { e.busy = false }()
+
+	e.saver()
+	e.refreshDefaults()
+	e.refreshOptions()
+
+	// Only copy the parts that changed. Diff skips funcs, which are never
+	// DeepEqual unless nil
+	current, snapshot := valueParts(e.root()), valueParts(e.snapshot)
+	var changed []int
+	for i := range current {
+		if len(schema.Diff(snapshot[i], current[i])) != 0 {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	before := make([]reflect.Value, len(changed))
+	after := make([]reflect.Value, len(changed))
+	for j, i := range changed {
+		before[j] = reflect.New(snapshot[i].Type()).Elem()
+		before[j].Set(snapshot[i])
+		after[j] = schema.Clone(current[i])
+		snapshot[i].Set(after[j])
+	}
+
+	pushed := false
+	cmd := qt.NewQUndoCommand2(text)
+	cmd.OnRedo(func(super func()) {
+		if !pushed {
+			pushed = true // QUndoStack calls redo() on push, but the change was already made
+			return
+		}
+		e.restore(changed, after)
+	})
+	cmd.OnUndo(func(super func()) {
+		e.restore(changed, before)
+	})
+	e.stack.Push(cmd)
+}
+
+// restore sets parts of the value from copies, and rebuilds the form to show
+// it. The parts are changed in place, so that fields keep their address.
+func (e *Editor) restore(parts []int, values []reflect.Value) {
+	e.busy = true
+	defer func() { e.busy = false }()
+
+	current, snapshot := valueParts(e.root()), valueParts(e.snapshot)
+	for j, i := range parts {
+		schema.Assign(current[i], values[j])
+		snapshot[i].Set(values[j])
+	}
+	e.rebuild()
+}
+
+// valueParts splits a value for recording undo steps: the exported fields of
+// a struct, or otherwise the whole value.
+func valueParts(rv reflect.Value) []reflect.Value {
+	if rv.Kind() != reflect.Struct {
+		return []reflect.Value{rv}
+	}
+
+	ret := make([]reflect.Value, 0, rv.NumField())
+	for i := 0; i < rv.NumField(); i++ {
+		if rv.Type().Field(i).IsExported() {
+			ret = append(ret, rv.Field(i))
+		}
+	}
+	return ret
+}
+
+// addUndoShortcuts adds Ctrl+Z and Ctrl+Shift+Z to the widget, for the editor
+// that is currently shown.
+func addUndoShortcuts(widget *qt.QWidget, current func() *Editor) {
+	undoAction := qt.NewQAction5(tr("Undo"), widget.QObject)
+	undoAction.SetShortcut(qt.NewQKeySequence2("Ctrl+Z"))
+	undoAction.OnTriggered(func() { current().Undo() })
+	widget.AddAction(undoAction)
+
+	redoAction := qt.NewQAction5(tr("Redo"), widget.QObject)
+	redoAction.SetShortcuts([]qt.QKeySequence{*qt.NewQKeySequence2("Ctrl+Shift+Z"), *qt.NewQKeySequence2("Ctrl+Y")})
+	redoAction.OnTriggered(func() { current().Redo() })
+	widget.AddAction(redoAction)
+}
+
+// edited records an undo step for the form, if anything has changed.
+// Renderers should call it when the user finishes an edit, e.g. from a widget
+// signal. Edits that were not reported are recorded before the next undo.
+func (f *formContext) edited(text string) {
+	if f.editor != nil {
+		f.editor.record(text)
+	}
+}
+
+// editText gets the undo step description for editing a field.
+func editText(label string) string {
+	return undoText("Edit", label)
+}
+
+// undoText gets the undo step description for an action on a field, e.g.
+// "Add Peers". The action is translated with a "%s" placeholder for the field.
+func undoText(action, label string) string {
+	if label == "" {
+		label = tr("item")
+	}
+	return fmt.Sprintf(tr(action+" %s"), schema.StripMnemonic(label))
+}
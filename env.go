@@ -0,0 +1,242 @@
+package autoconfig
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/mappu/autoconfig/schema"
+	qt "github.com/mappu/miqt/qt6"
+)
+
+// LoadFromEnv sets struct fields from environment variables.
+//
+// The environment variable name is taken from the `yenv` struct tag if present,
+// otherwise it is generated from the prefix and the field path, e.g. with
+// prefix "MYAPP" the field `Network.ListenPort` is read from
+// `MYAPP_NETWORK_LISTEN_PORT`.
+//
+// Values are parsed with the same rules as BindFlags. Slices are separated by
+// commas, and maps are written as comma-separated "key=value" pairs. For a
+// OneOf, the selected option can be set by name.
+// Fields without a matching environment variable are left unchanged.
+func LoadFromEnv(ct ConfigurableStruct, prefix string) error {
+	rv := reflect.ValueOf(ct)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("LoadFromEnv: expected non-nil pointer, got %s", rv.Type().String())
+	}
+
+	root := func() reflect.Value { return rv.Elem() }
+
+	var firstErr error
+	walkTextLeaves(rv.Type().Elem(), root, root, nil, func(leaf textLeaf) {
+		name := envName(prefix, leaf)
+		val, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+
+		err := envSet(leaf, val)
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s: %w", name, err)
+		}
+	})
+
+	return firstErr
+}
+
+// envName gets the environment variable name for a leaf.
+func envName(prefix string, leaf textLeaf) string {
+	if useName, ok := leaf.Field.Tag.Lookup("yenv"); ok {
+		return useName
+	}
+
+	parts := make([]string, 0, len(leaf.Path)+1)
+	if prefix != "" {
+		parts = append(parts, prefix)
+	}
+	for _, name := range leaf.Path {
+		parts = append(parts, strings.ToUpper(strings.ReplaceAll(schema.FormatLabel(name), " ", "_")))
+	}
+	return strings.Join(parts, "_")
+}
+
+func envSet(leaf textLeaf, val string) error {
+	switch leaf.Kind {
+	case textLeafValue:
+		return schema.ParseText(leaf.Resolve(), leaf.Field.Tag, val)
+
+	case textLeafSlice:
+		rv := leaf.Resolve()
+		rv.Set(reflect.MakeSlice(rv.Type(), 0, 0))
+		if val == "" {
+			return nil
+		}
+		for _, part := range strings.Split(val, ",") {
+			err := appendText(rv, leaf.Field.Tag, strings.TrimSpace(part))
+			if err != nil {
+				return err
+			}
+		}
+
+	case textLeafMap:
+		rv := leaf.Resolve()
+		rv.Set(reflect.MakeMap(rv.Type()))
+		if val == "" {
+			return nil
+		}
+		for _, part := range strings.Split(val, ",") {
+			err := insertMapText(rv, leaf.Field.Tag, strings.TrimSpace(part))
+			if err != nil {
+				return err
+			}
+		}
+
+	case textLeafOneOf:
+		return schema.SelectOneOfText(leaf.Resolve(), val)
+	}
+
+	return nil
+}
+
+// fieldKey identifies a single field in memory.
+type fieldKey struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+func fieldKeyOf(rv reflect.Value) fieldKey {
+	return fieldKey{rv.Addr().Pointer(), rv.Type()}
+}
+
+// findEnvOverrides finds every field in the struct that has a matching
+// environment variable, mapped to the environment variable's name.
+func findEnvOverrides(rv reflect.Value, prefix string) map[fieldKey]string {
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	ret := make(map[fieldKey]string)
+
+	root := func() reflect.Value { return rv }
+	walkTextLeaves(rv.Type(), root, root, nil, func(leaf textLeaf) {
+		name := envName(prefix, leaf)
+		if _, ok := os.LookupEnv(name); !ok {
+			return
+		}
+
+		target := leaf.Peek()
+		if !target.IsValid() {
+			return
+		}
+
+		if leaf.Kind == textLeafOneOf {
+			target = target.Field(0)
+		}
+
+		ret[fieldKeyOf(target)] = name
+	})
+
+	return ret
+}
+
+// followEnvOverrides makes the environment overrides of a value also apply to
+// a copy of it from schema.Clone, e.g. for editing the copy instead.
+func (f *formContext) followEnvOverrides(from, to reflect.Value) {
+	if len(f.envOverrides) == 0 {
+		return
+	}
+
+	ret := make(map[fieldKey]string, len(f.envOverrides))
+	for k, v := range f.envOverrides {
+		ret[k] = v
+	}
+
+	var walk func(from, to reflect.Value)
+	walk = func(from, to reflect.Value) {
+		if from.CanAddr() && to.CanAddr() {
+			if name, ok := f.envOverrides[fieldKeyOf(from)]; ok {
+				ret[fieldKeyOf(to)] = name
+			}
+		}
+
+		switch from.Kind() {
+		case reflect.Pointer:
+			if !from.IsNil() && !to.IsNil() {
+				walk(from.Elem(), to.Elem())
+			}
+
+		case reflect.Struct:
+			for i := 0; i < from.NumField(); i++ {
+				if from.Type().Field(i).IsExported() {
+					walk(from.Field(i), to.Field(i))
+				}
+			}
+
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < from.Len() && i < to.Len(); i++ {
+				walk(from.Index(i), to.Index(i))
+			}
+		}
+	}
+	walk(from, to)
+
+	f.envOverrides = ret
+}
+
+// envOverride checks if the field is overridden by an environment variable,
+// following any pointers.
+func (f *formContext) envOverride(rv reflect.Value) (string, bool) {
+	if f.envOverrides == nil {
+		return "", false
+	}
+
+	for {
+		if rv.CanAddr() {
+			if name, ok := f.envOverrides[fieldKeyOf(rv)]; ok {
+				return name, true
+			}
+		}
+
+		if rv.Kind() != reflect.Pointer || rv.IsNil() {
+			return "", false
+		}
+		rv = rv.Elem()
+	}
+}
+
+// handle_env_override shows the current value of a field that is overridden
+// by an environment variable. The value cannot be edited.
+func handle_env_override(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string, envName string, secret bool) SaveFunc {
+
+	target := *rv
+	for target.Kind() == reflect.Pointer && !target.IsNil() {
+		target = target.Elem()
+	}
+
+	var display string
+	if secret {
+		display = "••••••" // &bull;
+	} else if schema.IsText(target.Type()) {
+		display = schema.FormatText(target, tag)
+	} else {
+		display = formatValue(&target, tag)
+	}
+
+	rlabel := qt.NewQLabel3(fmt.Sprintf(tr("%s (set by $%s)"), display, envName))
+	rlabel.SetEnabled(false)
+	rlabel.SetToolTip(fmt.Sprintf(tr("This value is set by the %s environment variable, and can't be changed here."), envName))
+	addRow(area, label, rlabel.QWidget)
+
+	return func() {
+		// Keep the value from the environment
+	}
+}
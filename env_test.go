@@ -0,0 +1,122 @@
+package autoconfig
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mappu/autoconfig/schema"
+)
+
+type testEnvStruct struct {
+	ListenPort int
+	Explicit   string `yenv:"CUSTOM_NAME"`
+	Timeout    time.Duration
+	CacheSize  Bytes
+	Address    AddressPort
+	Peers      []string
+	Labels     map[string]int
+	Optional   *struct {
+		Enabled bool
+	}
+	Transport testFlagsTransport
+	Untouched string
+}
+
+func TestLoadFromEnv(t *testing.T) {
+
+	t.Setenv("APP_LISTEN_PORT", "8080")
+	t.Setenv("CUSTOM_NAME", "custom")
+	t.Setenv("APP_TIMEOUT", "2 hours")
+	t.Setenv("APP_CACHE_SIZE", "1GiB")
+	t.Setenv("APP_ADDRESS", "example.com:443")
+	t.Setenv("APP_PEERS", "a, b")
+	t.Setenv("APP_LABELS", "x=1,y=2")
+	t.Setenv("APP_OPTIONAL_ENABLED", "true")
+	t.Setenv("APP_TRANSPORT", "unix")
+	t.Setenv("APP_TRANSPORT_UNIX", "/tmp/sock")
+
+	cfg := testEnvStruct{Untouched: "original"}
+	err := LoadFromEnv(&cfg, "APP")
+	if err != nil {
+		t.Fatalf("LoadFromEnv: %v", err)
+	}
+
+	if cfg.ListenPort != 8080 || cfg.Explicit != "custom" || cfg.Untouched != "original" {
+		t.Errorf("primitives: got %#v", cfg)
+	}
+	if cfg.Timeout != 2*time.Hour {
+		t.Errorf("Timeout: got %v", cfg.Timeout)
+	}
+	if cfg.CacheSize != 1024*1024*1024 {
+		t.Errorf("CacheSize: got %v", cfg.CacheSize)
+	}
+	if cfg.Address != (AddressPort{"example.com", 443}) {
+		t.Errorf("Address: got %v", cfg.Address)
+	}
+	if !reflect.DeepEqual(cfg.Peers, []string{"a", "b"}) {
+		t.Errorf("Peers: got %v", cfg.Peers)
+	}
+	if !reflect.DeepEqual(cfg.Labels, map[string]int{"x": 1, "y": 2}) {
+		t.Errorf("Labels: got %v", cfg.Labels)
+	}
+	if cfg.Optional == nil || !cfg.Optional.Enabled {
+		t.Errorf("Optional: got %#v", cfg.Optional)
+	}
+	if cfg.Transport.Mode != "Unix" || cfg.Transport.Unix == nil || *cfg.Transport.Unix != "/tmp/sock" {
+		t.Errorf("Transport: got %#v", cfg.Transport)
+	}
+
+	// Overridden fields can be found by address
+	overrides := findEnvOverrides(reflect.ValueOf(&cfg), "APP")
+	if got := overrides[fieldKeyOf(reflect.ValueOf(&cfg.ListenPort).Elem())]; got != "APP_LISTEN_PORT" {
+		t.Errorf("findEnvOverrides(ListenPort): got %q", got)
+	}
+	if got := overrides[fieldKeyOf(reflect.ValueOf(&cfg.Transport.Mode).Elem())]; got != "APP_TRANSPORT" {
+		t.Errorf("findEnvOverrides(Transport): got %q", got)
+	}
+	if _, ok := overrides[fieldKeyOf(reflect.ValueOf(&cfg.Untouched).Elem())]; ok {
+		t.Errorf("findEnvOverrides(Untouched): expected no override")
+	}
+
+	// A copy for editing has the same overrides, e.g. for WithConfirmChanges
+	form := &formContext{envOverrides: overrides}
+	work := schema.Clone(reflect.ValueOf(&cfg))
+	form.followEnvOverrides(reflect.ValueOf(&cfg), work)
+	copied := work.Interface().(*testEnvStruct)
+	if got, _ := form.envOverride(reflect.ValueOf(&copied.ListenPort).Elem()); got != "APP_LISTEN_PORT" {
+		t.Errorf("followEnvOverrides(ListenPort): got %q", got)
+	}
+	if got, _ := form.envOverride(reflect.ValueOf(&copied.Transport.Mode).Elem()); got != "APP_TRANSPORT" {
+		t.Errorf("followEnvOverrides(Transport): got %q", got)
+	}
+	if _, ok := form.envOverride(reflect.ValueOf(&copied.Untouched).Elem()); ok {
+		t.Errorf("followEnvOverrides(Untouched): expected no override")
+	}
+
+	// Invalid values are reported
+	t.Setenv("APP_CACHE_SIZE", "lots")
+	if err := LoadFromEnv(&cfg, "APP"); err == nil {
+		t.Errorf("expected error for invalid value")
+	}
+}
+
+func TestLoadFromEnvEditableEnum(t *testing.T) {
+	SetEnumStringOptions("test-locales", []string{"en_US", "de_DE"})
+	defer SetEnumStringOptions("test-locales", nil)
+
+	var cfg struct {
+		Strict   EnumString `yenum:"test-locales"`
+		Editable EnumString `yenum:"test-locales" yeditable:""`
+	}
+
+	t.Setenv("APP_EDITABLE", "fr_FR")
+	if err := LoadFromEnv(&cfg, "APP"); err != nil || cfg.Editable != "fr_FR" {
+		t.Errorf("Editable: got %q, %v", cfg.Editable, err)
+	}
+
+	t.Setenv("APP_STRICT", "fr_FR")
+	if err := LoadFromEnv(&cfg, "APP"); err == nil {
+		t.Errorf("Strict: expected error for unknown option")
+	}
+}
@@ -0,0 +1,153 @@
+package autoconfig
+
+import (
+	"strings"
+
+	qt "github.com/mappu/miqt/qt6"
+)
+
+// filterNode is a part of the form that can be hidden by the search filter.
+// Each struct field is a node, and the fields of any nested struct are its
+// children.
+type filterNode struct {
+	text     string // Lowercase label, field name and help text
+	area     *qt.QFormLayout
+	firstRow int
+	endRow   int
+	label    *qt.QWidget // For highlighting, or nil
+	children []*filterNode
+
+	visible   bool
+	highlight bool
+
+	// setVisible replaces hiding the node's rows, e.g. for a tab
+	setVisible func(visible bool)
+
+	// reveal is called after filtering, e.g. to switch to a tab that has matches
+	reveal func(query string)
+}
+
+// filterScope runs fn, and records the rows that it adds to the area as a
+// filter node. Any nodes recorded by fn become its children.
+func (f *formContext) filterScope(area *qt.QFormLayout, text string, fn func()) *filterNode {
+	node := &filterNode{
+		text:     strings.ToLower(text),
+		area:     area,
+		firstRow: area.RowCount(),
+		visible:  true,
+	}
+
+	parent := f.filterParent
+	if parent != nil {
+		parent.children = append(parent.children, node)
+	}
+
+	f.filterParent = node
+	defer func() { f.filterParent = parent }()
+
+	fn()
+
+	node.endRow = area.RowCount()
+	if node.endRow > node.firstRow {
+		node.label = filterLabel(area, node.firstRow)
+	}
+	return node
+}
+
+// filterLabel finds the label widget for the row, if there is one.
+func filterLabel(area *qt.QFormLayout, row int) *qt.QWidget {
+	if item := area.ItemAt(row, qt.QFormLayout__LabelRole); item != nil {
+		return item.Widget()
+	}
+
+	// Rows without a separate label, e.g. a checkbox or a Header
+	for _, role := range []qt.QFormLayout__ItemRole{qt.QFormLayout__FieldRole, qt.QFormLayout__SpanningRole} {
+		if item := area.ItemAt(row, role); item != nil {
+			if w := item.Widget(); w != nil && (w.Inherits("QAbstractButton") || w.Inherits("QLabel")) {
+				return w
+			}
+		}
+	}
+
+	return nil
+}
+
+// match decides whether the node should be visible for the lowercase query.
+// Nodes are visible if they match, if any child matches, or if any parent
+// matches.
+func (n *filterNode) match(query string, parentMatched bool) bool {
+	ownMatch := strings.Contains(n.text, query)
+	n.highlight = ownMatch && query != "" && len(n.children) == 0
+
+	n.visible = parentMatched || ownMatch
+	for _, child := range n.children {
+		if child.match(query, parentMatched || ownMatch) {
+			n.visible = true
+		}
+	}
+
+	return n.visible
+}
+
+// apply updates the widgets, after match.
+func (n *filterNode) apply(query string) {
+	if n.setVisible != nil {
+		n.setVisible(n.visible)
+	} else {
+		for row := n.firstRow; row < n.endRow; row++ {
+			n.area.SetRowVisible(row, n.visible)
+		}
+	}
+
+	if n.label != nil {
+		if n.highlight {
+			n.label.SetStyleSheet("background-color: palette(highlight); color: palette(highlighted-text);")
+		} else {
+			n.label.SetStyleSheet("")
+		}
+	}
+
+	// Children after the parent, because the parent's rows include theirs
+	for _, child := range n.children {
+		child.apply(query)
+	}
+
+	if n.reveal != nil {
+		n.reveal(query)
+	}
+}
+
+// firstVisible gets the index of the first visible node, or -1.
+func firstVisible(nodes []*filterNode) int {
+	for i, n := range nodes {
+		if n.visible {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetFilter shows only the fields that match the text, by their label, field
+// name or `yhelp` text, and highlights the matches. Tabs and OneOf pages
+// without any matches are switched away from. Use an empty string to show
+// every field again.
+func (e *Editor) SetFilter(text string) {
+	e.filterText = text
+	e.applyFilter()
+}
+
+func (e *Editor) applyFilter() {
+	query := strings.ToLower(strings.TrimSpace(e.filterText))
+	e.filterRoot.match(query, false)
+	e.filterRoot.visible = true // Keep any rows outside of a struct
+	e.filterRoot.apply(query)
+}
+
+// addFilterBox adds a search box to the layout.
+func addFilterBox(layout *qt.QBoxLayout, setFilter func(text string)) {
+	search := qt.NewQLineEdit2()
+	search.SetPlaceholderText(tr("Search..."))
+	search.SetClearButtonEnabled(true)
+	search.OnTextChanged(setFilter)
+	layout.AddWidget(search.QWidget)
+}
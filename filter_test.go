@@ -0,0 +1,45 @@
+package autoconfig
+
+import (
+	"testing"
+)
+
+func TestFilterMatch(t *testing.T) {
+	port := &filterNode{text: "listen port\nlistenport\nthe tcp port"}
+	addr := &filterNode{text: "address\naddress\n"}
+	network := &filterNode{text: "network\nnetwork\n", children: []*filterNode{port, addr}}
+	cache := &filterNode{text: "cache size\ncachesize\n"}
+	root := &filterNode{children: []*filterNode{network, cache}}
+
+	type testCase struct {
+		query   string
+		visible []*filterNode
+	}
+
+	cases := []testCase{
+		{"", []*filterNode{root, network, port, addr, cache}},
+		{"tcp", []*filterNode{root, network, port}},           // By help text
+		{"network", []*filterNode{root, network, port, addr}}, // Parent match shows all children
+		{"nothing", nil},
+	}
+
+	for _, tc := range cases {
+		root.match(tc.query, false)
+
+		want := make(map[*filterNode]bool)
+		for _, n := range tc.visible {
+			want[n] = true
+		}
+
+		for i, n := range []*filterNode{root, network, port, addr, cache} {
+			if n.visible != want[n] {
+				t.Errorf("match(%q): node %d visible=%v, want %v", tc.query, i, n.visible, want[n])
+			}
+		}
+	}
+
+	root.match("tcp", false)
+	if !port.highlight || network.highlight {
+		t.Errorf("match(%q): expected only the matching field to be highlighted", "tcp")
+	}
+}
@@ -0,0 +1,218 @@
+package autoconfig
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+
+	"github.com/mappu/autoconfig/schema"
+)
+
+// BindFlags registers command-line flags on the FlagSet for every field in the
+// struct, so that the same settings can be configured in the GUI and on the
+// command line.
+//
+// Flag names are derived from the field path, e.g. the field `Network.ListenPort`
+// becomes `-network.listen-port`. The usage text is taken from the `yhelp` tag,
+// or from the field's label.
+//
+// Values are parsed with the same rules as the GUI: Factor types (including
+// Bytes and time.Duration) accept a unit suffix such as "10MiB" or "5minutes",
+// EnumList and EnumString accept option names, Flags accept names separated
+// by "|" such as "Read|Write", and AddressPort accepts "host:port". Slice
+// fields may be repeated to add multiple items, and map fields may be repeated
+// with "key=value".
+//
+// Pointer fields are allocated when any child flag is set. For a OneOf, the
+// selected option can be set by name, and setting any flag of an option will
+// also select it.
+// Types that cannot be represented on the command line are skipped. If
+// several fields have the same flag name, only one is bound, preferring the
+// least deeply embedded field and then the first. Flags that are already
+// defined in the FlagSet are left as they are.
+func BindFlags(ct ConfigurableStruct, fs *flag.FlagSet) {
+	rv := reflect.ValueOf(ct)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		panic("BindFlags: expected non-nil pointer, got " + rv.Type().String())
+	}
+
+	root := func() reflect.Value { return rv.Elem() }
+
+	// Different fields may have the same flag name, e.g. FooBar and Foo_Bar, or
+	// a field promoted from an embedded struct. Like Go's own field promotion,
+	// the least deeply embedded field wins, and then the first one.
+	var leaves []textLeaf
+	byName := map[string]int{}
+	walkTextLeaves(rv.Type().Elem(), root, root, nil, func(leaf textLeaf) {
+		name := flagName(leaf.Path)
+		if prev, ok := byName[name]; ok {
+			if leaf.Depth < leaves[prev].Depth {
+				leaves[prev] = leaf
+			}
+			return
+		}
+		byName[name] = len(leaves)
+		leaves = append(leaves, leaf)
+	})
+
+	for _, leaf := range leaves {
+		name := flagName(leaf.Path)
+		if fs.Lookup(name) != nil {
+			continue // Already defined by the caller
+		}
+
+		switch leaf.Kind {
+		case textLeafValue:
+			fs.Var(&flagValue{
+				resolve: leaf.Resolve,
+				tag:     leaf.Field.Tag,
+				def:     flagDefault(leaf.Peek, leaf.Field.Tag),
+				isBool:  leaf.Type.Kind() == reflect.Bool,
+			}, name, flagUsage(leaf.Field))
+
+		case textLeafSlice:
+			fs.Var(&flagSliceValue{resolve: leaf.Resolve, tag: leaf.Field.Tag}, name, flagUsage(leaf.Field)+" (may be repeated)")
+
+		case textLeafMap:
+			fs.Var(&flagMapValue{resolve: leaf.Resolve, tag: leaf.Field.Tag}, name, flagUsage(leaf.Field)+" (key=value, may be repeated)")
+
+		case textLeafOneOf:
+			selected := ""
+			if rv := leaf.Peek(); rv.IsValid() {
+				selected = rv.Field(0).String()
+			}
+
+			fs.Var(&flagOneOfValue{resolve: leaf.Resolve, def: selected}, name,
+				"Select one of: "+strings.Join(schema.OneOfOptions(leaf.Type), ", "))
+		}
+	}
+}
+
+// flagName converts a struct field path into a flag name.
+func flagName(path []string) string {
+	parts := make([]string, 0, len(path))
+	for _, name := range path {
+		parts = append(parts, strings.ToLower(strings.ReplaceAll(schema.FormatLabel(name), " ", "-")))
+	}
+	return strings.Join(parts, ".")
+}
+
+// flagUsage builds the usage text for a field.
+func flagUsage(ff reflect.StructField) string {
+	usage := ff.Tag.Get("yhelp")
+	if usage == "" {
+		usage = schema.Label(ff)
+	}
+
+	t := ff.Type
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if factors, ok := schema.Factors(t, ff.Tag); ok {
+		var units []string
+		for _, fac := range factors {
+			units = append(units, fac.Label)
+		}
+		usage += " (units: " + strings.Join(units, ", ") + ")"
+
+	} else if flags, ok := schema.Flags(t, ff.Tag); ok {
+		var names []string
+		for _, fl := range flags {
+			names = append(names, fl.Label)
+		}
+		usage += " (any of, separated by |: " + strings.Join(names, ", ") + ")"
+
+	} else if t == reflect.TypeOf(EnumList(0)) {
+		usage += " (one of: " + strings.Join(schema.EnumListOptions(ff.Tag.Get("yenum")), ", ") + ")"
+
+	} else if t == reflect.TypeOf(EnumString("")) {
+		if opts, ok := schema.EnumStringOptions(ff.Tag.Get("yenum")); ok && schema.EnumEditable(ff.Tag) {
+			usage += " (e.g. " + strings.Join(opts, ", ") + ")"
+		} else if ok {
+			usage += " (one of: " + strings.Join(opts, ", ") + ")"
+		}
+	}
+
+	return usage
+}
+
+// flagDefault formats the current value of the target for the usage text.
+func flagDefault(peek func() reflect.Value, tag reflect.StructTag) string {
+	rv := peek()
+	if !rv.IsValid() {
+		return ""
+	}
+	return schema.FormatText(rv, tag)
+}
+
+// flagValue is a flag.Value for any single text type.
+type flagValue struct {
+	resolve func() reflect.Value
+	tag     reflect.StructTag
+	def     string
+	isBool  bool
+}
+
+func (v *flagValue) String() string {
+	return v.def
+}
+
+func (v *flagValue) Set(s string) error {
+	return schema.ParseText(v.resolve(), v.tag, s)
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+// flagSliceValue is a flag.Value that appends to a slice each time the flag
+// is repeated. The first use replaces any default items.
+type flagSliceValue struct {
+	resolve func() reflect.Value
+	tag     reflect.StructTag
+	seen    bool
+}
+
+func (v *flagSliceValue) String() string {
+	return ""
+}
+
+func (v *flagSliceValue) Set(s string) error {
+	rv := v.resolve()
+	if !v.seen {
+		rv.SetLen(0)
+		v.seen = true
+	}
+
+	return appendText(rv, v.tag, s)
+}
+
+// flagMapValue is a flag.Value that inserts into a map each time the flag is
+// repeated, in "key=value" format.
+type flagMapValue struct {
+	resolve func() reflect.Value
+	tag     reflect.StructTag
+}
+
+func (v *flagMapValue) String() string {
+	return ""
+}
+
+func (v *flagMapValue) Set(s string) error {
+	return insertMapText(v.resolve(), v.tag, s)
+}
+
+// flagOneOfValue is a flag.Value for the selected option of a OneOf.
+type flagOneOfValue struct {
+	resolve func() reflect.Value
+	def     string
+}
+
+func (v *flagOneOfValue) String() string {
+	return v.def
+}
+
+func (v *flagOneOfValue) Set(s string) error {
+	return schema.SelectOneOfText(v.resolve(), s)
+}
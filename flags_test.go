@@ -0,0 +1,162 @@
+package autoconfig
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testFlagsTransportTCP struct {
+	Port int
+}
+
+type testFlagsTransport struct {
+	Mode OneOf
+	TCP  *testFlagsTransportTCP
+	Unix *ExistingFile
+}
+
+type testFlagsStruct struct {
+	ListenPort int `yhelp:"Port to listen on"`
+	Verbose    bool
+	Name       string `ylabel:"Display name"`
+	Timeout    time.Duration
+	CacheSize  Bytes
+	Level      EnumList `yenum:"Low;;Medium;;High"`
+	Address    AddressPort
+	Peers      []string
+	Labels     map[string]int
+	Inner      struct {
+		Enabled bool
+	}
+	Optional  *testFlagsTransportTCP
+	Transport testFlagsTransport
+	Header    Header
+	hidden    int
+}
+
+func TestBindFlags(t *testing.T) {
+
+	cfg := testFlagsStruct{
+		Timeout: 30 * time.Second,
+		Peers:   []string{"default"},
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	BindFlags(&cfg, fs)
+
+	if got := fs.Lookup("listen-port"); got == nil || got.Usage != "Port to listen on" {
+		t.Errorf("listen-port: got %#v", got)
+	}
+	if got := fs.Lookup("timeout"); got == nil || got.DefValue != "30s" {
+		t.Errorf("timeout: got %#v", got)
+	}
+	if got := fs.Lookup("optional.port"); got == nil || got.DefValue != "" {
+		t.Errorf("optional.port: got %#v", got)
+	}
+
+	err := fs.Parse([]string{
+		"-listen-port", "8080",
+		"-verbose",
+		"-name", "hello",
+		"-timeout", "5minutes",
+		"-cache-size", "10MiB",
+		"-level", "high",
+		"-address", "[::1]:443",
+		"-peers", "a", "-peers", "b",
+		"-labels", "x=1",
+		"-inner.enabled",
+		"-optional.port", "99",
+		"-transport.tcp.port", "1234",
+	})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if cfg.ListenPort != 8080 || !cfg.Verbose || cfg.Name != "hello" {
+		t.Errorf("primitives: got %#v", cfg)
+	}
+	if cfg.Timeout != 5*time.Minute {
+		t.Errorf("Timeout: got %v", cfg.Timeout)
+	}
+	if cfg.CacheSize != 10*1024*1024 {
+		t.Errorf("CacheSize: got %v", cfg.CacheSize)
+	}
+	if cfg.Level != 2 {
+		t.Errorf("Level: got %v", cfg.Level)
+	}
+	if cfg.Address != (AddressPort{"::1", 443}) {
+		t.Errorf("Address: got %v", cfg.Address)
+	}
+	if !reflect.DeepEqual(cfg.Peers, []string{"a", "b"}) {
+		t.Errorf("Peers: got %v", cfg.Peers)
+	}
+	if !reflect.DeepEqual(cfg.Labels, map[string]int{"x": 1}) {
+		t.Errorf("Labels: got %v", cfg.Labels)
+	}
+	if !cfg.Inner.Enabled {
+		t.Errorf("Inner.Enabled: got false")
+	}
+	if cfg.Optional == nil || cfg.Optional.Port != 99 {
+		t.Errorf("Optional: got %#v", cfg.Optional)
+	}
+	if cfg.Transport.Mode != "TCP" || cfg.Transport.TCP == nil || cfg.Transport.TCP.Port != 1234 {
+		t.Errorf("Transport: got %#v", cfg.Transport)
+	}
+
+	// Selecting another OneOf option clears the previous one
+	err = fs.Parse([]string{"-transport", "unix", "-transport.unix", "/tmp/sock"})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.Transport.Mode != "Unix" || cfg.Transport.TCP != nil || cfg.Transport.Unix == nil || *cfg.Transport.Unix != "/tmp/sock" {
+		t.Errorf("Transport: got %#v", cfg.Transport)
+	}
+
+	// Invalid values
+	for _, args := range [][]string{
+		{"-level", "extreme"},
+		{"-cache-size", "10 parsecs"},
+		{"-transport", "carrier-pigeon"},
+	} {
+		if err := fs.Parse(args); err == nil {
+			t.Errorf("Parse(%v): expected error", args)
+		}
+	}
+}
+
+type TestFlagsCommon struct {
+	Name    string
+	Verbose bool
+}
+
+type testFlagsCollision struct {
+	TestFlagsCommon
+	Name     string
+	Foo_Bar  int
+	FooBar   int
+	Existing string
+}
+
+func TestBindFlagsCollision(t *testing.T) {
+	var cfg testFlagsCollision
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("existing", "", "defined by the caller")
+	BindFlags(&cfg, fs)
+
+	err := fs.Parse([]string{"-name", "outer", "-verbose", "-foo-bar", "1"})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if cfg.Name != "outer" || cfg.TestFlagsCommon.Name != "" || !cfg.Verbose {
+		t.Errorf("promoted field: got %+v", cfg)
+	}
+	if cfg.Foo_Bar != 1 || cfg.FooBar != 0 {
+		t.Errorf("same name: got Foo_Bar=%d FooBar=%d", cfg.Foo_Bar, cfg.FooBar)
+	}
+}
@@ -0,0 +1,113 @@
+package autoconfig
+
+import (
+	"reflect"
+
+	"github.com/mappu/autoconfig/schema"
+	qt "github.com/mappu/miqt/qt6"
+)
+
+// formContext holds the options and state that are shared by every renderer in
+// a single form. Renderers don't receive it as a parameter, instead it is
+// available as activeForm while the form is being constructed.
+//
+// Renderers that open a nested dialog later (e.g. on a button click) should
+// capture activeForm during construction, and pass it to openDialogFor.
+type formContext struct {
+	options
+
+	envOverrides map[fieldKey]string
+	editor       *Editor       // For recording undo steps, if any
+	filterParent *filterNode   // Node for the struct currently being built, if any
+	tree         *treeDialog   // The tree layout dialog that nested values open in, if any
+	path         string        // Dotted path of the value being built, see fieldScope
+	owner        reflect.Value // The struct that has the field being built, if any, for Suggester
+}
+
+// activeForm is the form currently being constructed.
+// Construction always happens on the Qt main thread.
+var activeForm = &formContext{}
+
+func newFormContext(rv *reflect.Value, opts []Option) *formContext {
+	ret := &formContext{
+		options: makeOptions(opts),
+	}
+
+	if ret.envPrefix != nil {
+		ret.envOverrides = findEnvOverrides(*rv, *ret.envPrefix)
+	}
+
+	return ret
+}
+
+// build runs the function with this form as the activeForm.
+func (f *formContext) build(fn func() SaveFunc) SaveFunc {
+	prev := activeForm
+	activeForm = f
+	defer func() { activeForm = prev }()
+
+	return fn()
+}
+
+// buildReadOnly runs the function with a read-only copy of this form as the
+// activeForm, e.g. for a field with the `yreadonly` tag. Nothing can be
+// changed, so the returned SaveFunc does nothing.
+func (f *formContext) buildReadOnly(fn func() SaveFunc) SaveFunc {
+	ro := *f
+	ro.readOnly = true
+	ro.build(fn)
+
+	return func() {}
+}
+
+// nested gets the form context to use for a nested dialog. Options that only
+// apply to the top-level dialog are removed.
+func (f *formContext) nested() *formContext {
+	ret := *f
+	ret.codecs = nil
+	ret.confirm = false // Changes are reviewed in the top-level dialog
+	ret.editor = nil    // The nested dialog has its own
+	ret.path = ""       // Paths in the nested dialog start from its value
+	return &ret
+}
+
+// fieldScope runs the function with the named child of the current value as
+// the activeForm path, e.g. "Network" and then "Network.Port". An empty name,
+// for embedded structs, keeps the current path.
+// Afterwards, any widgets in the new rows that are not named already are named
+// after the path, see ObjectName.
+func (f *formContext) fieldScope(area *qt.QFormLayout, name string, fn func()) {
+	parent := f.path
+	if name != "" {
+		f.path = schema.JoinPath(parent, name)
+	}
+	defer func() { f.path = parent }()
+
+	firstRow := area.RowCount()
+	fn()
+
+	for _, w := range rowWidgets(area, firstRow, area.RowCount()) {
+		if w.ObjectName() == "" {
+			w.SetObjectName(*qt.NewQAnyStringView3(ObjectName(f.path)))
+		}
+	}
+}
+
+// ObjectName gets the Qt object name of the widget for the field at the path
+// in a form, e.g. "Network.Port", for finding it in tests. The path is the
+// same as for schema.Schema.Lookup, starting from the value of the form or
+// nested dialog. Fields that span several rows, e.g. nested structs, don't
+// have a widget of their own.
+// See the autoconfigtest package for a test driver.
+func ObjectName(path string) string {
+	return "autoconfig:" + path
+}
+
+// addRefresher adds a function that updates a widget after each edit in the
+// form, e.g. for options that depend on other fields. See
+// Editor.RefreshOptions.
+func (f *formContext) addRefresher(fn func()) {
+	if f.editor != nil {
+		f.editor.refreshers = append(f.editor.refreshers, fn)
+	}
+}
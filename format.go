@@ -3,14 +3,19 @@ package autoconfig
 import (
 	"fmt"
 	"reflect"
-	"strings"
+
+	"github.com/mappu/autoconfig/schema"
 )
 
 // formatValue tries to format a plaintext summary of a reflect.Value.
-func formatValue(rv *reflect.Value) string {
+// The tag is used for any `yflags` names, e.g. "Read|Write".
+func formatValue(rv *reflect.Value, tag reflect.StructTag) string {
 
 	if rv.Kind() == reflect.Pointer && rv.IsNil() {
-		return "Not configured"
+		return tr("Not configured")
+
+	} else if flags, ok := schema.Flags(rv.Type(), tag); ok {
+		return schema.FormatFlags(flags, rv.Uint())
 
 	} else if stringer, ok := rv.Interface().(fmt.Stringer); ok { // n.b. matches if we have a T and (T) String() exists with value reciever
 		return stringer.String()
@@ -50,51 +55,10 @@ func formatValue(rv *reflect.Value) string {
 		if rv.Kind() == reflect.Pointer && !rv.IsNil() {
 			// Pointer to something stringable?
 			childItem := rv.Elem()
-			childDisplayname := formatValue(&childItem)
+			childDisplayname := formatValue(&childItem, tag)
 			return "(" + childDisplayname + ")"
 		}
 
-		return "Configured"
-	}
-}
-
-// formatLabel tries to generate a nice label from the automatic struct field.
-func formatLabel(s string) string {
-	// Mode: convert _ as spaces
-	if strings.Contains(s, `_`) {
-		return strings.TrimSpace(strings.ReplaceAll(s, `_`, ` `))
-	}
-
-	// Mode: convert CamelCase to spaces
-	// Handle embedded acronyms (e.g. "TLSConfig" -> "TLS Config")
-	var ret string
-	var hold string // single previous uppercase character
-	for i, ch := range s {
-		_ = i
-		if string(ch) == strings.ToUpper(string(ch)) {
-			// uppercase
-			if len(hold) == 0 && len(ret) > 0 && ret[len(ret)-1] != ' ' {
-				// first uppercase after a previous lowercase = new word
-				ret += " "
-			}
-
-			ret += hold
-			hold = string(ch)
-		} else {
-			// lowercase
-			if len(hold) > 0 {
-				// first lowercase after a previous uppercase
-				if len(ret) > 0 && ret[len(ret)-1] != ' ' {
-					ret += " "
-				}
-				ret += hold
-				hold = ""
-			}
-			ret += string(ch)
-		}
-	}
-	if len(hold) > 0 {
-		ret += hold
+		return tr("Configured")
 	}
-	return ret
 }
@@ -19,7 +19,7 @@ func TestFormatValue(t *testing.T) {
 
 	for _, tc := range cases {
 		rv := reflect.ValueOf(tc.input)
-		got := formatValue(&rv)
+		got := formatValue(&rv, "")
 		if got != tc.expect {
 			t.Errorf("formatValue(%q): got %q, want %q", tc.input, got, tc.expect)
 		}
@@ -27,35 +27,15 @@ func TestFormatValue(t *testing.T) {
 
 }
 
-func TestFormatLabel(t *testing.T) {
-	type testCase struct {
-		input, expect string
-	}
-
-	cases := []testCase{
-		// Simple case
-		{"foo", "foo"},
-		{"Foo", "Foo"},
-
-		// Underscore style
-		{"Foo_Bar", "Foo Bar"},
-		{"__Foo_Bar__", "Foo Bar"},
+func TestFormatValueFlags(t *testing.T) {
+	perms := []Flags{3, 0x41}
+	tag := reflect.StructTag(`yflags:"1;;Read;;2;;Write;;4;;Execute"`)
 
-		// Camelcase style
-		{"FooBar", "Foo Bar"},
-		{"FoBa", "Fo Ba"},
-		{"FoB", "Fo B"},
-		{"FBa", "F Ba"},
-		{"FB", "FB"},
-		{"TLSConfig", "TLS Config"},
-		{"ConfigTLS", "Config TLS"},
-		{"PrefixACRONYMSuffix", "Prefix ACRONYM Suffix"},
-	}
-
-	for _, tc := range cases {
-		got := formatLabel(tc.input)
-		if got != tc.expect {
-			t.Errorf("formatLabel(%q): got %q, want %q", tc.input, got, tc.expect)
+	expect := []string{"Read|Write", "Read|0x40"}
+	for i := range perms {
+		rv := reflect.ValueOf(perms).Index(i)
+		if got := formatValue(&rv, tag); got != expect[i] {
+			t.Errorf("formatValue(%d): got %q, want %q", perms[i], got, expect[i])
 		}
 	}
 }
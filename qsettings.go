@@ -0,0 +1,361 @@
+package autoconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	qt "github.com/mappu/miqt/qt6"
+)
+
+// SaveToQSettings writes the struct into a QSettings object, under the supplied
+// group name. If the group is blank, fields are written at the top level.
+//
+// Child structs are written as groups, slices and maps are written as arrays,
+// OneOf structs store the selected field name, and nil pointers are removed.
+// Factor types (including Bytes and time.Duration) are written as integers.
+//
+// The QSettings object may use either the platform-native backend or an INI
+// file. Call settings.Sync() afterwards to flush changes to disk immediately.
+func SaveToQSettings(ct ConfigurableStruct, settings *qt.QSettings, group string) {
+	rv := reflect.ValueOf(ct)
+	if rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return // Nothing to save
+	}
+
+	if group != "" {
+		settings.BeginGroup(qsKey(group))
+		defer settings.EndGroup()
+	}
+
+	qsSave(settings, rv, "")
+}
+
+// LoadFromQSettings reads the struct from a QSettings object, under the supplied
+// group name, using the same layout as SaveToQSettings.
+// Fields that are not present in the QSettings object are left unchanged.
+func LoadFromQSettings(ct ConfigurableStruct, settings *qt.QSettings, group string) error {
+	rv := reflect.ValueOf(ct)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("LoadFromQSettings: expected non-nil pointer, got %s", rv.Type().String())
+	}
+
+	if group != "" {
+		settings.BeginGroup(qsKey(group))
+		defer settings.EndGroup()
+	}
+
+	return qsLoad(settings, rv.Elem(), "")
+}
+
+func qsKey(key string) qt.QAnyStringView {
+	return *qt.NewQAnyStringView3(key)
+}
+
+// qsHas checks if the key exists in the current group, either as a value or as
+// a child group.
+func qsHas(settings *qt.QSettings, key string) bool {
+	if settings.Contains(qsKey(key)) {
+		return true
+	}
+	for _, child := range settings.ChildGroups() {
+		if child == key {
+			return true
+		}
+	}
+	return false
+}
+
+// qsElemKey is the key used for each entry inside an array. Structs are written
+// directly into the array entry, other types need a key name.
+func qsElemKey(t reflect.Type) string {
+	if t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{}) {
+		return ""
+	}
+	return "value"
+}
+
+// qsSortedKeys gets all map keys, in a stable order.
+func qsSortedKeys(rv reflect.Value) []reflect.Value {
+	keys := rv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	return keys
+}
+
+func qsSave(settings *qt.QSettings, rv reflect.Value, key string) {
+
+	if rv.Type() == reflect.TypeOf(time.Time{}) {
+		settings.SetValue(qsKey(key), qt.NewQVariant11(rv.Interface().(time.Time).Format(time.RFC3339Nano)))
+		return
+
+	} else if rv.Type() == reflect.TypeOf([]byte{}) {
+		settings.SetValue(qsKey(key), qt.NewQVariant12(rv.Bytes()))
+		return
+	}
+
+	switch rv.Kind() {
+	case reflect.Func, reflect.UnsafePointer, reflect.Chan, reflect.Interface:
+		// No way we can store these types
+
+	case reflect.Bool:
+		settings.SetValue(qsKey(key), qt.NewQVariant8(rv.Bool()))
+
+	case reflect.String:
+		settings.SetValue(qsKey(key), qt.NewQVariant11(rv.String()))
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		settings.SetValue(qsKey(key), qt.NewQVariant6(rv.Int()))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		settings.SetValue(qsKey(key), qt.NewQVariant7(rv.Uint()))
+
+	case reflect.Float32, reflect.Float64:
+		settings.SetValue(qsKey(key), qt.NewQVariant9(rv.Float()))
+
+	case reflect.Complex64, reflect.Complex128:
+		settings.SetValue(qsKey(key), qt.NewQVariant11(strconv.FormatComplex(rv.Complex(), 'g', -1, rv.Type().Bits())))
+
+	case reflect.Pointer:
+		if rv.IsNil() {
+			// Store an empty value, so that loading clears the pointer too
+			settings.Remove(qsKey(key))
+			settings.SetValue(qsKey(key), qt.NewQVariant())
+			return
+		}
+		qsSave(settings, rv.Elem(), key)
+
+	case reflect.Struct:
+		if key != "" {
+			settings.BeginGroup(qsKey(key))
+			defer settings.EndGroup()
+		}
+
+		obj := rv.Type()
+		nf := obj.NumField()
+		for i := 0; i < nf; i++ {
+			ff := obj.Field(i)
+
+			if i == 0 && ff.Type == reflect.TypeOf(OneOf("")) {
+				qsSaveOneOf(settings, rv)
+				return
+			}
+
+			if !ff.IsExported() || ff.Type.Kind() == reflect.Struct && ff.Type.NumField() == 0 {
+				continue // Private fields, Header, TabGroup
+			}
+
+			qsSave(settings, rv.Field(i), ff.Name)
+		}
+
+	case reflect.Slice, reflect.Array:
+		settings.Remove(qsKey(key)) // Remove any trailing entries from a previous longer array
+
+		elemKey := qsElemKey(rv.Type().Elem())
+		settings.BeginWriteArray2(qsKey(key), rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			settings.SetArrayIndex(i)
+			qsSave(settings, rv.Index(i), elemKey)
+		}
+		settings.EndArray()
+
+	case reflect.Map:
+		settings.Remove(qsKey(key))
+
+		keys := qsSortedKeys(rv)
+		settings.BeginWriteArray2(qsKey(key), len(keys))
+		for i, mk := range keys {
+			settings.SetArrayIndex(i)
+			qsSave(settings, mk, "key")
+			qsSave(settings, rv.MapIndex(mk), "value")
+		}
+		settings.EndArray()
+
+	default:
+		panic("SaveToQSettings missing handling for type=" + rv.Type().String())
+	}
+}
+
+func qsSaveOneOf(settings *qt.QSettings, rv reflect.Value) {
+	obj := rv.Type()
+
+	selected := rv.Field(0).String()
+	settings.SetValue(qsKey(obj.Field(0).Name), qt.NewQVariant11(selected))
+
+	for i := 1; i < obj.NumField(); i++ {
+		ff := obj.Field(i)
+		if ff.Name == selected {
+			qsSave(settings, rv.Field(i), ff.Name)
+		} else {
+			settings.Remove(qsKey(ff.Name))
+		}
+	}
+}
+
+func qsLoad(settings *qt.QSettings, rv reflect.Value, key string) error {
+
+	if rv.Type() == reflect.TypeOf(time.Time{}) {
+		t, err := time.Parse(time.RFC3339Nano, settings.ValueWithKey(qsKey(key)).ToString())
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		rv.Set(reflect.ValueOf(t))
+		return nil
+
+	} else if rv.Type() == reflect.TypeOf([]byte{}) {
+		rv.SetBytes(settings.ValueWithKey(qsKey(key)).ToByteArray())
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Func, reflect.UnsafePointer, reflect.Chan, reflect.Interface:
+		// No way we can load these types
+
+	case reflect.Bool:
+		rv.SetBool(settings.ValueWithKey(qsKey(key)).ToBool())
+
+	case reflect.String:
+		rv.SetString(settings.ValueWithKey(qsKey(key)).ToString())
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		rv.SetInt(settings.ValueWithKey(qsKey(key)).ToLongLong())
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		rv.SetUint(settings.ValueWithKey(qsKey(key)).ToULongLong())
+
+	case reflect.Float32, reflect.Float64:
+		rv.SetFloat(settings.ValueWithKey(qsKey(key)).ToDouble())
+
+	case reflect.Complex64, reflect.Complex128:
+		c, err := strconv.ParseComplex(settings.ValueWithKey(qsKey(key)).ToString(), rv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		rv.SetComplex(c)
+
+	case reflect.Pointer:
+		if !qsHas(settings, key) {
+			return nil // Not present, leave unchanged
+		}
+		if settings.Contains(qsKey(key)) && !settings.ValueWithKey(qsKey(key)).IsValid() {
+			rv.SetZero() // Saved as nil
+			return nil
+		}
+
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+
+			if defaulter, ok := rv.Interface().(Resetter); ok {
+				defaulter.Reset()
+			}
+		}
+		return qsLoad(settings, rv.Elem(), key)
+
+	case reflect.Struct:
+		if key != "" {
+			settings.BeginGroup(qsKey(key))
+			defer settings.EndGroup()
+		}
+
+		obj := rv.Type()
+		nf := obj.NumField()
+		for i := 0; i < nf; i++ {
+			ff := obj.Field(i)
+
+			if i == 0 && ff.Type == reflect.TypeOf(OneOf("")) {
+				return qsLoadOneOf(settings, rv)
+			}
+
+			if !ff.IsExported() || ff.Type.Kind() == reflect.Struct && ff.Type.NumField() == 0 {
+				continue // Private fields, Header, TabGroup
+			}
+
+			if !qsHas(settings, ff.Name) {
+				continue // Not present, leave unchanged
+			}
+
+			err := qsLoad(settings, rv.Field(i), ff.Name)
+			if err != nil {
+				return err
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		elemKey := qsElemKey(rv.Type().Elem())
+		size := settings.BeginReadArray(qsKey(key))
+		defer settings.EndArray()
+
+		if rv.Kind() == reflect.Slice {
+			rv.Set(reflect.MakeSlice(rv.Type(), size, size))
+		} else if size > rv.Len() {
+			size = rv.Len() // Fixed-size array, ignore any extra entries
+		}
+
+		for i := 0; i < size; i++ {
+			settings.SetArrayIndex(i)
+			err := qsLoad(settings, rv.Index(i), elemKey)
+			if err != nil {
+				return fmt.Errorf("%s[%d]: %w", key, i, err)
+			}
+		}
+
+	case reflect.Map:
+		size := settings.BeginReadArray(qsKey(key))
+		defer settings.EndArray()
+
+		rv.Set(reflect.MakeMapWithSize(rv.Type(), size))
+
+		for i := 0; i < size; i++ {
+			settings.SetArrayIndex(i)
+
+			mk := reflect.New(rv.Type().Key()).Elem()
+			err := qsLoad(settings, mk, "key")
+			if err != nil {
+				return fmt.Errorf("%s[%d]: %w", key, i, err)
+			}
+
+			mv := reflect.New(rv.Type().Elem()).Elem()
+			err = qsLoad(settings, mv, "value")
+			if err != nil {
+				return fmt.Errorf("%s[%d]: %w", key, i, err)
+			}
+
+			rv.SetMapIndex(mk, mv)
+		}
+
+	default:
+		panic("LoadFromQSettings missing handling for type=" + rv.Type().String())
+	}
+
+	return nil
+}
+
+func qsLoadOneOf(settings *qt.QSettings, rv reflect.Value) error {
+	obj := rv.Type()
+
+	selectorKey := obj.Field(0).Name
+	if !settings.Contains(qsKey(selectorKey)) {
+		return nil // Not present, leave unchanged
+	}
+
+	selected := settings.ValueWithKey(qsKey(selectorKey)).ToString()
+	rv.Field(0).SetString(selected)
+
+	for i := 1; i < obj.NumField(); i++ {
+		ff := obj.Field(i)
+		if ff.Name != selected {
+			rv.Field(i).SetZero()
+			continue
+		}
+
+		err := qsLoad(settings, rv.Field(i), ff.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package autoconfig
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	qt "github.com/mappu/miqt/qt6"
+)
+
+type testQSettingsInner struct {
+	Enabled bool
+}
+
+type testQSettingsStruct struct {
+	Name     string
+	Port     uint16
+	Ratio    float64
+	Timeout  time.Duration
+	When     time.Time
+	Data     []byte
+	Peers    []string
+	Labels   map[string]int
+	Inner    testQSettingsInner
+	Optional *testQSettingsInner
+	Backend  testFlagsTransport
+}
+
+func newTestQSettings(t *testing.T) *qt.QSettings {
+	settings := qt.NewQSettings4(filepath.Join(t.TempDir(), "test.ini"), qt.QSettings__IniFormat)
+	t.Cleanup(settings.Delete)
+	return settings
+}
+
+func TestQSettingsRoundTrip(t *testing.T) {
+	src := testQSettingsStruct{
+		Name:     "example",
+		Port:     8080,
+		Ratio:    0.5,
+		Timeout:  5 * time.Minute,
+		When:     time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Data:     []byte{1, 2, 3},
+		Peers:    []string{"a", "b"},
+		Labels:   map[string]int{"x": 1, "y": 2},
+		Inner:    testQSettingsInner{Enabled: true},
+		Optional: &testQSettingsInner{Enabled: true},
+	}
+	sock := ExistingFile("/tmp/sock")
+	src.Backend = testFlagsTransport{Mode: "Unix", Unix: &sock}
+
+	settings := newTestQSettings(t)
+	SaveToQSettings(&src, settings, "app")
+	settings.Sync()
+
+	var dst testQSettingsStruct
+	if err := LoadFromQSettings(&dst, settings, "app"); err != nil {
+		t.Fatalf("LoadFromQSettings: %v", err)
+	}
+	if !reflect.DeepEqual(dst, src) {
+		t.Errorf("got %+v, want %+v", dst, src)
+	}
+
+	// A nil pointer is stored as an empty value, and then loads as nil, even
+	// into a struct where the pointer is already set
+	src.Optional = nil
+	SaveToQSettings(&src, settings, "app")
+	settings.Sync()
+
+	reopened := qt.NewQSettings4(settings.FileName(), qt.QSettings__IniFormat)
+	defer reopened.Delete()
+
+	dst = testQSettingsStruct{Optional: &testQSettingsInner{Enabled: true}}
+	if err := LoadFromQSettings(&dst, reopened, "app"); err != nil || dst.Optional != nil {
+		t.Errorf("Optional: got %+v, %v", dst.Optional, err)
+	}
+}
+
+func TestQSettingsLoadPartial(t *testing.T) {
+	settings := newTestQSettings(t)
+	settings.SetValue(qsKey("Name"), qt.NewQVariant11("loaded"))
+
+	dst := testQSettingsStruct{
+		Port:     443,
+		Optional: &testQSettingsInner{Enabled: true},
+	}
+	if err := LoadFromQSettings(&dst, settings, ""); err != nil {
+		t.Fatalf("LoadFromQSettings: %v", err)
+	}
+
+	if dst.Name != "loaded" || dst.Port != 443 {
+		t.Errorf("got %+v", dst)
+	}
+	if dst.Optional == nil || !dst.Optional.Enabled {
+		t.Errorf("Optional: absent pointer should be unchanged, got %+v", dst.Optional)
+	}
+}
@@ -0,0 +1,117 @@
+package autoconfig
+
+import (
+	"reflect"
+
+	"github.com/mappu/autoconfig/schema"
+	qt "github.com/mappu/miqt/qt6"
+)
+
+// changeReview makes a dialog edit a copy of its value, for WithConfirmChanges.
+// The copy is only applied to the original value after the user reviews and
+// confirms the changes.
+type changeReview struct {
+	original  *reflect.Value
+	working   *reflect.Value
+	confirmed bool
+}
+
+func newChangeReview(rv *reflect.Value) *changeReview {
+	working := schema.Clone(*rv)
+	return &changeReview{original: rv, working: &working}
+}
+
+// addOkButton adds the OK button to a dialog. With a review, there is also a
+// Cancel button, and OK shows the changes for review before accepting.
+// The save function saves the form into the value that it edits.
+func addOkButton(dlg *qt.QDialog, buttons *qt.QDialogButtonBox, review *changeReview, save func()) {
+	if review == nil {
+		buttons.SetStandardButtons(qt.QDialogButtonBox__Ok)
+		buttons.OnAccepted(dlg.Accept)
+		return
+	}
+
+	buttons.SetStandardButtons(qt.QDialogButtonBox__Ok | qt.QDialogButtonBox__Cancel)
+	buttons.OnAccepted(func() {
+		save()
+
+		changes := schema.Diff(*review.original, *review.working)
+		if len(changes) > 0 && !reviewChanges(dlg.QWidget, changes) {
+			return // Go back to editing
+		}
+
+		review.confirmed = true
+		dlg.Accept()
+	})
+}
+
+// apply copies the edited value into the original, if the changes were
+// confirmed. Otherwise, they are discarded.
+func (r *changeReview) apply() {
+	if r == nil || !r.confirmed {
+		return
+	}
+	reflect.Indirect(*r.original).Set(reflect.Indirect(*r.working))
+}
+
+// reviewChanges shows a modal dialog that lists the changes, with the old and
+// new values. It returns true if the user confirms them.
+func reviewChanges(parent *qt.QWidget, changes ChangeSet) bool {
+	dlg := qt.NewQDialog(parent)
+	dlg.SetModal(true)
+	dlg.SetWindowTitle(tr("Review changes"))
+	defer dlg.DeleteLater()
+
+	list := qt.NewQTreeWidget2()
+	list.SetColumnCount(3)
+	list.SetHeaderLabels([]string{tr("Field"), tr("Old value"), tr("New value")})
+	list.SetRootIsDecorated(false)
+	list.SetUniformRowHeights(true)
+	list.SetSelectionMode(qt.QAbstractItemView__NoSelection)
+
+	for _, c := range changes {
+		label := c.Label
+		if label == "" {
+			label = tr(defaultLabel) // The value itself, e.g. in a slice element's dialog
+		}
+
+		item := qt.NewQTreeWidgetItem2([]string{label, reviewValue(c, c.Old), reviewValue(c, c.New)})
+		item.SetToolTip(0, c.Path)
+		list.AddTopLevelItem(item)
+	}
+	for col := 0; col < 3; col++ {
+		list.ResizeColumnToContents(col)
+	}
+
+	buttons := qt.NewQDialogButtonBox(dlg.QWidget)
+	buttons.AddButton2(tr("Go back"), qt.QDialogButtonBox__RejectRole)
+	applyBtn := buttons.AddButton2(tr("Apply changes"), qt.QDialogButtonBox__AcceptRole)
+	applyBtn.SetDefault(true)
+	buttons.OnAccepted(dlg.Accept)
+	buttons.OnRejected(dlg.Reject)
+
+	vbox := qt.NewQVBoxLayout(dlg.QWidget)
+	vbox.AddWidget(qt.NewQLabel3(tr("The following settings will be changed:")).QWidget)
+	vbox.AddWidget(list.QWidget)
+	vbox.AddWidget(buttons.QWidget)
+
+	dlg.Resize(600, 400)
+	return dlg.Exec() == int(qt.QDialog__Accepted)
+}
+
+// reviewValue formats one side of a change for the review dialog.
+func reviewValue(c Change, v any) string {
+	switch {
+	case c.Kind == schema.ChangeSelected:
+		return v.(string) // The OneOf option's field name
+	case v == nil && c.Kind == schema.ChangeAdded:
+		return tr("(added)")
+	case v == nil && c.Kind == schema.ChangeRemoved:
+		return tr("(removed)")
+	case c.Secret:
+		return "••••••••" // &bull;
+	}
+
+	rv := reflect.ValueOf(v)
+	return formatValue(&rv, c.Tag)
+}
@@ -0,0 +1,87 @@
+package schema
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// IntBounds gets the allowed range for a signed integer type, from the size
+// of the type and the optional `ymin` and `ymax` struct tags.
+// It panics if a tag is malformed, as this is a programmer error.
+func IntBounds(t reflect.Type, tag reflect.StructTag) (min, max int64) {
+	bits := t.Bits()
+	min = -1 << (bits - 1)
+	max = 1<<(bits-1) - 1
+
+	if s, ok := tag.Lookup("ymin"); ok {
+		min = parseIntTag(s, bits)
+	}
+	if s, ok := tag.Lookup("ymax"); ok {
+		max = parseIntTag(s, bits)
+	}
+	return min, max
+}
+
+// UintBounds gets the allowed range for an unsigned integer type, from the
+// size of the type and the optional `ymin` and `ymax` struct tags.
+// It panics if a tag is malformed, as this is a programmer error.
+func UintBounds(t reflect.Type, tag reflect.StructTag) (min, max uint64) {
+	bits := t.Bits()
+	max = math.MaxUint64 >> (64 - bits)
+
+	if s, ok := tag.Lookup("ymin"); ok {
+		min = parseUintTag(s, bits)
+	}
+	if s, ok := tag.Lookup("ymax"); ok {
+		max = parseUintTag(s, bits)
+	}
+	return min, max
+}
+
+// FloatBounds gets the allowed range for a float type, from the optional
+// `ymin` and `ymax` struct tags.
+// It panics if a tag is malformed, as this is a programmer error.
+func FloatBounds(tag reflect.StructTag) (min, max float64) {
+	min = -math.MaxFloat64
+	max = math.MaxFloat64
+
+	if s, ok := tag.Lookup("ymin"); ok {
+		min = parseFloatTag(s)
+	}
+	if s, ok := tag.Lookup("ymax"); ok {
+		max = parseFloatTag(s)
+	}
+	return min, max
+}
+
+// HasBounds checks if the struct tag sets any numeric bounds.
+func HasBounds(tag reflect.StructTag) bool {
+	_, hasMin := tag.Lookup("ymin")
+	_, hasMax := tag.Lookup("ymax")
+	return hasMin || hasMax
+}
+
+func parseIntTag(s string, bits int) int64 {
+	ret, err := strconv.ParseInt(s, 10, bits)
+	if err != nil {
+		panic(err) // Programmer error
+	}
+	return ret
+}
+
+func parseUintTag(s string, bits int) uint64 {
+	ret, err := strconv.ParseUint(s, 10, bits)
+	if err != nil {
+		panic(err) // Programmer error
+	}
+	return ret
+}
+
+func parseFloatTag(s string) float64 {
+	ret, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		panic(err) // Programmer error
+	}
+	return ret
+}
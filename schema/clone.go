@@ -0,0 +1,180 @@
+package schema
+
+import (
+	"reflect"
+)
+
+// Clone makes a deep copy of a value, so that the copy can be changed without
+// affecting the original.
+//
+// Pointers, slices and maps are copied recursively. Funcs, channels, interfaces
+// and unexported fields are shared with the original. A pointer that is
+// reached twice, e.g. a back-pointer to a parent, is only copied once, so the
+// copy has the same cycles as the original.
+func Clone(rv reflect.Value) reflect.Value {
+	ret := reflect.New(rv.Type()).Elem()
+	c := cloner{}
+	c.seeRoot(ret, rv)
+	c.cloneInto(ret, rv)
+	return ret
+}
+
+// ptrKey identifies a pointer. The type is included, because a struct and its
+// first field have the same address.
+type ptrKey struct {
+	t    reflect.Type
+	addr uintptr
+}
+
+// cloner tracks the pointers that were already copied, from the source
+// pointer to the pointer in the copy.
+type cloner struct {
+	seen map[ptrKey]reflect.Value
+}
+
+func (c *cloner) see(src, dst reflect.Value) {
+	if c.seen == nil {
+		c.seen = map[ptrKey]reflect.Value{}
+	}
+	c.seen[ptrKey{src.Type(), src.Pointer()}] = dst
+}
+
+// seeRoot records the root value, if it has an address, so that a pointer back
+// to it is mapped to the copy.
+func (c *cloner) seeRoot(dst, src reflect.Value) {
+	if src.CanAddr() && dst.CanAddr() {
+		c.see(src.Addr(), dst.Addr())
+	}
+}
+
+func (c *cloner) lookup(src reflect.Value) (reflect.Value, bool) {
+	dst, ok := c.seen[ptrKey{src.Type(), src.Pointer()}]
+	return dst, ok
+}
+
+func (c *cloner) cloneInto(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		if ptr, ok := c.lookup(src); ok {
+			dst.Set(ptr)
+			return
+		}
+		ptr := reflect.New(src.Type().Elem())
+		c.see(src, ptr)
+		c.cloneInto(ptr.Elem(), src.Elem())
+		dst.Set(ptr)
+
+	case reflect.Struct:
+		dst.Set(src) // Includes unexported fields
+		for i := 0; i < src.NumField(); i++ {
+			if src.Type().Field(i).IsExported() {
+				c.cloneInto(dst.Field(i), src.Field(i))
+			}
+		}
+
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		slice := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			c.cloneInto(slice.Index(i), src.Index(i))
+		}
+		dst.Set(slice)
+
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			c.cloneInto(dst.Index(i), src.Index(i))
+		}
+
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(c.clone(iter.Key()), c.clone(iter.Value()))
+		}
+		dst.Set(m)
+
+	default:
+		dst.Set(src)
+	}
+}
+
+func (c *cloner) clone(rv reflect.Value) reflect.Value {
+	ret := reflect.New(rv.Type()).Elem()
+	c.cloneInto(ret, rv)
+	return ret
+}
+
+// Assign sets dst to a deep copy of src, in the same way as Clone, but keeps
+// the pointers that are already in dst where both values have one. Fields
+// inside them keep the same address, e.g. for widgets that are bound to them.
+//
+// Slices are reallocated if their length changes, and maps are always
+// replaced. The values must have the same type, and dst must be settable.
+// Cycles are handled in the same way as Clone.
+func Assign(dst, src reflect.Value) {
+	c := cloner{}
+	c.seeRoot(dst, src)
+	c.assign(dst, src)
+}
+
+func (c *cloner) assign(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			dst.SetZero()
+			return
+		}
+		if ptr, ok := c.lookup(src); ok {
+			dst.Set(ptr)
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.New(src.Type().Elem()))
+		}
+		c.see(src, dst.Elem().Addr())
+		c.assign(dst.Elem(), src.Elem())
+
+	case reflect.Struct:
+		// Copy the unexported fields, then the exported fields over the
+		// previous values
+		prev := reflect.New(dst.Type()).Elem()
+		prev.Set(dst)
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if src.Type().Field(i).IsExported() {
+				dst.Field(i).Set(prev.Field(i))
+				c.assign(dst.Field(i), src.Field(i))
+			}
+		}
+
+	case reflect.Slice:
+		if src.IsNil() {
+			dst.SetZero()
+			return
+		}
+		if dst.IsNil() || dst.Len() != src.Len() {
+			slice := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+			reflect.Copy(slice, dst)
+			dst.Set(slice)
+		}
+		for i := 0; i < src.Len(); i++ {
+			c.assign(dst.Index(i), src.Index(i))
+		}
+
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			c.assign(dst.Index(i), src.Index(i))
+		}
+
+	default:
+		c.cloneInto(dst, src)
+	}
+}
@@ -0,0 +1,75 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DefaultProvider is implemented by configurable structs that compute the
+// default values of their fields, e.g. from the current platform. It takes
+// precedence over the `ydefault` tag and Reset().
+//
+// Default gets the default value for the named struct field, or false if the
+// field has no computed default. The value must be convertible to the field's
+// type.
+type DefaultProvider interface {
+	Default(field string) (any, bool)
+}
+
+var resetterType = reflect.TypeOf((*interface{ Reset() })(nil)).Elem()
+
+// Default gets the default value of a field of the struct value, from the
+// first of:
+//   - The DefaultProvider of the struct that has the field
+//   - The field's `ydefault` tag, parsed with ParseText (e.g. "10MiB", "5m" or
+//     an enum option name)
+//   - The field's value after calling Reset() on a new struct
+//   - The field's type, after calling Reset() on a new value of it
+//
+// The field may be promoted from an embedded struct, see FormFields. It
+// returns false if there is no default, in which case the zero value is used.
+func Default(parent reflect.Value, f *Field) (reflect.Value, bool) {
+	path := f.IndexPath
+	if path == nil {
+		path = []int{f.Index}
+	}
+
+	owner := parent.FieldByIndex(path[:len(path)-1]) // The struct that has the field
+	index := path[len(path)-1]
+
+	if owner.CanAddr() {
+		if provider, ok := owner.Addr().Interface().(DefaultProvider); ok {
+			if val, ok := provider.Default(f.Name); ok {
+				rv := reflect.ValueOf(val)
+				if !rv.IsValid() || !rv.Type().ConvertibleTo(f.Type) {
+					// programmer error
+					panic(fmt.Sprintf("DefaultProvider: default for field '%s' is %T, expected %v", f.Name, val, f.Type))
+				}
+				return rv.Convert(f.Type), true
+			}
+		}
+	}
+
+	if s, ok := f.Tag.Lookup("ydefault"); ok {
+		ret := reflect.New(f.Type).Elem()
+		if err := ParseText(ret, f.Tag, s); err != nil {
+			// programmer error
+			panic(fmt.Sprintf("ydefault: invalid default for field '%s': %v", f.Name, err))
+		}
+		return ret, true
+	}
+
+	if reflect.PointerTo(owner.Type()).Implements(resetterType) {
+		tmp := reflect.New(owner.Type())
+		tmp.Interface().(interface{ Reset() }).Reset()
+		return tmp.Elem().Field(index), true
+	}
+
+	if reflect.PointerTo(f.Type).Implements(resetterType) {
+		tmp := reflect.New(f.Type)
+		tmp.Interface().(interface{ Reset() }).Reset()
+		return tmp.Elem(), true
+	}
+
+	return reflect.Value{}, false
+}
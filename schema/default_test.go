@@ -0,0 +1,58 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// DefaultsBase is exported, so that it is not skipped when embedded
+type DefaultsBase struct {
+	Retries int
+}
+
+func (t *DefaultsBase) Reset() {
+	t.Retries = 3
+}
+
+type testDefaults struct {
+	DefaultsBase
+
+	Port    int           `ydefault:"8080"`
+	Timeout time.Duration `ydefault:"5m"`
+	Name    string        `ydefault:"ignored"`
+	Plain   string
+}
+
+func (t *testDefaults) Default(field string) (any, bool) {
+	if field == "Name" {
+		return "computed", true
+	}
+	return nil, false
+}
+
+func TestDefault(t *testing.T) {
+	var cfg testDefaults
+	rv := reflect.ValueOf(&cfg).Elem()
+
+	expect := map[string]any{
+		"Port":    8080,
+		"Timeout": 5 * time.Minute,
+		"Name":    "computed", // DefaultProvider takes precedence over ydefault
+		"Retries": 3,          // Reset() on the embedded struct
+		"Plain":   "",         // Reset() is promoted to the outer struct too
+	}
+
+	for _, f := range FormFields(rv.Type()) {
+		got, ok := Default(rv, f)
+
+		want, hasWant := expect[f.Name]
+		if ok != hasWant {
+			t.Errorf("%s: got ok=%v, want %v", f.Name, ok, hasWant)
+			continue
+		}
+		if ok && !reflect.DeepEqual(got.Interface(), want) {
+			t.Errorf("%s: got %v, want %v", f.Name, got.Interface(), want)
+		}
+	}
+}
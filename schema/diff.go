@@ -0,0 +1,249 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeKind is the type of a Change.
+type ChangeKind int
+
+const (
+	ChangeModified ChangeKind = iota // The value was edited
+	ChangeAdded                      // A slice element or map key was inserted, or a pointer was allocated
+	ChangeRemoved                    // A slice element or map key was deleted, or a pointer was cleared
+	ChangeSelected                   // A different OneOf option was selected
+)
+
+var changeKindNames = []string{"modified", "added", "removed", "selected"}
+
+func (k ChangeKind) String() string {
+	if k >= 0 && int(k) < len(changeKindNames) {
+		return changeKindNames[k]
+	}
+	return "ChangeKind(" + fmt.Sprint(int(k)) + ")"
+}
+
+// Change describes a single modification of a configurable struct.
+type Change struct {
+	// Path is the dotted field path, in the same format as Schema.Lookup but
+	// with the slice index or map key, e.g. "Peers[2].Name" or "Labels[env]".
+	Path   string
+	Label  string     // Display labels along the path, e.g. "Network > Listen port"
+	Kind   ChangeKind // How the value was changed
+	Old    any        // The old value, or nil if it was added
+	New    any        // The new value, or nil if it was removed. For ChangeSelected, the option's field name
+	Secret bool       // The values should not be displayed or logged
+
+	// Tag is the struct tag of the field, for formatting the values, e.g.
+	// with `yfactor` or `yflags`. Slice, array and map elements use the tag
+	// of their container.
+	Tag reflect.StructTag
+}
+
+// String describes the change for a log, e.g. "Network.Port: 80 -> 8080".
+// Secret values are masked.
+func (c Change) String() string {
+	format := func(v any) string {
+		if c.Secret {
+			return "********"
+		}
+		return fmt.Sprint(v)
+	}
+
+	switch c.Kind {
+	case ChangeAdded:
+		return c.Path + ": added " + format(c.New)
+	case ChangeRemoved:
+		return c.Path + ": removed " + format(c.Old)
+	case ChangeSelected:
+		return c.Path + ": selected " + fmt.Sprint(c.New) + " instead of " + fmt.Sprint(c.Old)
+	}
+	return c.Path + ": " + format(c.Old) + " -> " + format(c.New)
+}
+
+// ChangeSet lists the changes between two values of a configurable struct,
+// depth-first in field order.
+type ChangeSet []Change
+
+// Affects checks if the field at the path, or anything inside it, was
+// changed, e.g. "Network" for a change to "Network.Port".
+func (cs ChangeSet) Affects(path string) bool {
+	for _, c := range cs {
+		if path == "" || c.Path == path || strings.HasPrefix(c.Path, path+".") || strings.HasPrefix(c.Path, path+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff lists the changes from the value before to the value after, which must
+// have the same type, e.g. a copy from Clone and the value after editing.
+//
+// Structs are compared field by field, including hidden fields. Slices and
+// arrays are compared by index, except that a run of inserted or deleted
+// elements is reported as ChangeAdded or ChangeRemoved. Values of other types
+// are reported as a single ChangeModified. Each pair of pointers is only
+// compared once, so cycles are allowed.
+func Diff(before, after reflect.Value) ChangeSet {
+	d := differ{}
+	for before.Kind() == reflect.Pointer && after.Kind() == reflect.Pointer && !before.IsNil() && !after.IsNil() {
+		d.see(before, after)
+		before, after = before.Elem(), after.Elem()
+	}
+
+	d.diff("", "", false, "", before, after)
+	return d.ret
+}
+
+type differ struct {
+	ret  ChangeSet
+	seen map[[2]ptrKey]bool // Pointer pairs that were compared, for cycles
+}
+
+// see records that two pointers are compared, and reports if they weren't
+// already.
+func (d *differ) see(before, after reflect.Value) bool {
+	pair := [2]ptrKey{{before.Type(), before.Pointer()}, {after.Type(), after.Pointer()}}
+	if d.seen[pair] {
+		return false
+	}
+	if d.seen == nil {
+		d.seen = map[[2]ptrKey]bool{}
+	}
+	d.seen[pair] = true
+	return true
+}
+
+func (d *differ) add(path, label string, secret bool, tag reflect.StructTag, kind ChangeKind, before, after any) {
+	d.ret = append(d.ret, Change{Path: path, Label: label, Kind: kind, Old: before, New: after, Secret: secret, Tag: tag})
+}
+
+func (d *differ) diff(path, label string, secret bool, tag reflect.StructTag, before, after reflect.Value) {
+	if before.Kind() == reflect.Func || reflect.DeepEqual(before.Interface(), after.Interface()) {
+		return // Functions can't be compared, and can't be edited either
+	}
+
+	switch Classify(before.Type(), tag) {
+	case KindStruct, KindTabGroup, KindGroupBox:
+		d.diffStruct(path, label, secret, before, after)
+
+	case KindOneOf:
+		beforeOpt, afterOpt := before.Field(0).String(), after.Field(0).String()
+		if beforeOpt != afterOpt {
+			d.add(path, label, false, tag, ChangeSelected, beforeOpt, afterOpt)
+			return // The other option's values are new, not modified
+		}
+		d.diffStruct(path, label, secret, before, after)
+
+	case KindPointer:
+		switch {
+		case before.IsNil():
+			d.add(path, label, secret, tag, ChangeAdded, nil, after.Elem().Interface())
+		case after.IsNil():
+			d.add(path, label, secret, tag, ChangeRemoved, before.Elem().Interface(), nil)
+		default:
+			if !d.see(before, after) {
+				return // Already compared, e.g. a back-pointer to a parent
+			}
+			d.diff(path, label, secret, tag, before.Elem(), after.Elem())
+		}
+
+	case KindSlice, KindArray:
+		d.diffSlice(path, label, secret, tag, before, after)
+
+	case KindMap:
+		d.diffMap(path, label, secret, tag, before, after)
+
+	default:
+		d.add(path, label, secret, tag, ChangeModified, before.Interface(), after.Interface())
+	}
+}
+
+func (d *differ) diffStruct(path, label string, secret bool, before, after reflect.Value) {
+	t := before.Type()
+	for i := 0; i < t.NumField(); i++ {
+		ff := t.Field(i)
+		if !ff.IsExported() || (i == 0 && Classify(t, "") != KindStruct) {
+			continue // Private, or the OneOf, TabGroup or GroupBox marker
+		}
+
+		fieldPath, fieldLabel := path, label
+		if !ff.Anonymous {
+			fieldPath = JoinPath(path, ff.Name)
+			fieldLabel = joinLabel(label, FieldLabel(t, ff))
+		}
+
+		d.diff(fieldPath, fieldLabel, secret || isSecret(ff), ff.Tag, before.Field(i), after.Field(i))
+	}
+}
+
+func (d *differ) diffSlice(path, label string, secret bool, tag reflect.StructTag, before, after reflect.Value) {
+	beforeLen, afterLen := before.Len(), after.Len()
+
+	// Skip the unchanged elements at the start and end
+	start := 0
+	for start < beforeLen && start < afterLen && reflect.DeepEqual(before.Index(start).Interface(), after.Index(start).Interface()) {
+		start++
+	}
+	end := 0
+	for end < beforeLen-start && end < afterLen-start && reflect.DeepEqual(before.Index(beforeLen-1-end).Interface(), after.Index(afterLen-1-end).Interface()) {
+		end++
+	}
+
+	// Elements in both are modified, and then the rest were added or removed
+	i := start
+	for ; i < beforeLen-end && i < afterLen-end; i++ {
+		d.diff(indexPath(path, i), indexPath(label, i), secret, tag, before.Index(i), after.Index(i))
+	}
+	for j := i; j < beforeLen-end; j++ {
+		d.add(indexPath(path, j), indexPath(label, j), secret, tag, ChangeRemoved, before.Index(j).Interface(), nil)
+	}
+	for j := i; j < afterLen-end; j++ {
+		d.add(indexPath(path, j), indexPath(label, j), secret, tag, ChangeAdded, nil, after.Index(j).Interface())
+	}
+}
+
+func (d *differ) diffMap(path, label string, secret bool, tag reflect.StructTag, before, after reflect.Value) {
+	keys := map[string]reflect.Value{}
+	for _, k := range before.MapKeys() {
+		keys[fmt.Sprint(k.Interface())] = k
+	}
+	for _, k := range after.MapKeys() {
+		keys[fmt.Sprint(k.Interface())] = k
+	}
+
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		k := keys[name]
+		keyPath, keyLabel := path+"["+name+"]", label+"["+name+"]"
+
+		beforeVal, afterVal := before.MapIndex(k), after.MapIndex(k)
+		switch {
+		case !beforeVal.IsValid():
+			d.add(keyPath, keyLabel, secret, tag, ChangeAdded, nil, afterVal.Interface())
+		case !afterVal.IsValid():
+			d.add(keyPath, keyLabel, secret, tag, ChangeRemoved, beforeVal.Interface(), nil)
+		default:
+			d.diff(keyPath, keyLabel, secret, tag, beforeVal, afterVal)
+		}
+	}
+}
+
+func indexPath(path string, i int) string {
+	return path + "[" + fmt.Sprint(i) + "]"
+}
+
+func joinLabel(parent, label string) string {
+	if parent == "" {
+		return label
+	}
+	return parent + " > " + label
+}
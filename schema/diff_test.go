@@ -0,0 +1,89 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testDiffPeer struct {
+	Name string
+}
+
+type testDiffConfig struct {
+	Port     int
+	Password string
+	Peers    []testDiffPeer
+	Labels   map[string]string
+	Proxy    *testDiffPeer
+}
+
+func TestDiff(t *testing.T) {
+	before := testDiffConfig{
+		Port:     80,
+		Password: "hunter2",
+		Peers:    []testDiffPeer{{"a"}, {"b"}, {"c"}},
+		Labels:   map[string]string{"env": "dev", "old": "x"},
+	}
+
+	after := Clone(reflect.ValueOf(before)).Interface().(testDiffConfig)
+	after.Port = 8080
+	after.Password = "swordfish"
+	after.Peers = []testDiffPeer{{"a"}, {"c"}, {"d"}}
+	after.Labels = map[string]string{"env": "prod", "new": "y"}
+	after.Proxy = &testDiffPeer{"p"}
+
+	got := Diff(reflect.ValueOf(before), reflect.ValueOf(after))
+
+	expect := []string{
+		"Port: 80 -> 8080",
+		"Password: ******** -> ********",
+		"Peers[1].Name: b -> c",
+		"Peers[2].Name: c -> d",
+		"Labels[env]: dev -> prod",
+		"Labels[new]: added y",
+		"Labels[old]: removed x",
+		"Proxy: added {p}",
+	}
+
+	var gotText []string
+	for _, c := range got {
+		gotText = append(gotText, c.String())
+	}
+	if !reflect.DeepEqual(gotText, expect) {
+		t.Errorf("got %q, want %q", gotText, expect)
+	}
+
+	if !got.Affects("Peers") || got.Affects("Pee") || !got.Affects("Labels[env]") {
+		t.Errorf("Affects: wrong result")
+	}
+}
+
+func TestDiffSliceInsert(t *testing.T) {
+	before := []string{"a", "b", "c"}
+	after := []string{"a", "x", "y", "b", "c"}
+
+	got := Diff(reflect.ValueOf(before), reflect.ValueOf(after))
+	if len(got) != 2 || got[0].Path != "[1]" || got[0].Kind != ChangeAdded || got[1].Path != "[2]" || got[1].Kind != ChangeAdded {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestDiffTag(t *testing.T) {
+	type config struct {
+		Mode  uint8   `yflags:"1;;Read;;2;;Write"`
+		Modes []uint8 `yflags:"1;;Read;;2;;Write"`
+	}
+
+	before := config{Mode: 1}
+	after := config{Mode: 3, Modes: []uint8{2}}
+
+	got := Diff(reflect.ValueOf(before), reflect.ValueOf(after))
+	if len(got) != 2 {
+		t.Fatalf("got %v", got)
+	}
+	for _, c := range got {
+		if flags, ok := Flags(reflect.TypeOf(uint8(0)), c.Tag); !ok || len(flags) != 2 {
+			t.Errorf("%s: got tag %q", c.Path, c.Tag)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+// Package schema describes how autoconfig sees a Go struct, independently of
+// any GUI toolkit.
+//
+// It contains the rules for walking a struct (tag lookup, label formatting,
+// OneOf/TabGroup/GroupBox detection, and field heuristics) and for converting
+// values to and from text, so that other frontends, documentation and tests can
+// share the same model as the Qt renderer without creating a QApplication.
+package schema
@@ -0,0 +1,131 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	enumStringMu    sync.RWMutex
+	enumStringOpts  map[string][]string
+	enumStringFuncs map[string]func(parent any) []string
+)
+
+// SetEnumStringOptions configures the list of allowed options for the given key
+// when used with the autoconfig.EnumString type.
+// This is package-global and threadsafe.
+// To unregister a key, set the 'options' to nil.
+func SetEnumStringOptions(key string, options []string) {
+	enumStringMu.Lock()
+	defer enumStringMu.Unlock()
+
+	if enumStringOpts == nil {
+		enumStringOpts = make(map[string][]string)
+	}
+
+	if options == nil {
+		delete(enumStringOpts, key)
+	} else {
+		enumStringOpts[key] = options
+	}
+}
+
+// EnumStringOptions gets the list of allowed options for the given key, if it
+// was registered with SetEnumStringOptions.
+func EnumStringOptions(key string) ([]string, bool) {
+	enumStringMu.RLock()
+	defer enumStringMu.RUnlock()
+
+	opts, ok := enumStringOpts[key]
+	return opts, ok
+}
+
+// SetEnumStringFunc configures a function that computes the options for the
+// given key when a form is shown, e.g. from a sibling field or from devices
+// discovered at runtime. The function receives a pointer to the struct that
+// has the field. It takes precedence over SetEnumStringOptions.
+// This is package-global and threadsafe.
+// To unregister a key, set the 'fn' to nil.
+func SetEnumStringFunc(key string, fn func(parent any) []string) {
+	enumStringMu.Lock()
+	defer enumStringMu.Unlock()
+
+	if enumStringFuncs == nil {
+		enumStringFuncs = make(map[string]func(parent any) []string)
+	}
+
+	if fn == nil {
+		delete(enumStringFuncs, key)
+	} else {
+		enumStringFuncs[key] = fn
+	}
+}
+
+// EnumProvider is implemented by configurable structs that compute the options
+// of their autoconfig.EnumString fields, e.g. the list of profiles from another
+// field. It takes precedence over SetEnumStringFunc and SetEnumStringOptions.
+//
+// EnumOptions gets the options for the named struct field, or false to use the
+// field's `yenum` key instead.
+type EnumProvider interface {
+	EnumOptions(field string) ([]string, bool)
+}
+
+// EnumStringOptionsFor gets the options for an autoconfig.EnumString field,
+// from the first of:
+//   - The EnumProvider of the owner, the struct that has the field (if valid)
+//   - The function registered with SetEnumStringFunc for the `yenum` key
+//   - The options registered with SetEnumStringOptions for the `yenum` key
+//
+// It returns false if there are no options, e.g. an unknown key.
+func EnumStringOptionsFor(owner reflect.Value, field string, tag reflect.StructTag) ([]string, bool) {
+	if owner.IsValid() && owner.CanAddr() {
+		if provider, ok := owner.Addr().Interface().(EnumProvider); ok {
+			if opts, ok := provider.EnumOptions(field); ok {
+				return opts, true
+			}
+		}
+	}
+
+	key := tag.Get("yenum")
+
+	enumStringMu.RLock()
+	fn := enumStringFuncs[key]
+	enumStringMu.RUnlock()
+
+	if fn != nil && owner.IsValid() && owner.CanAddr() {
+		return fn(owner.Addr().Interface()), true
+	}
+
+	return EnumStringOptions(key)
+}
+
+// EnumEditable checks if an autoconfig.EnumString field has the `yeditable`
+// tag, to allow entering values other than the registered options.
+func EnumEditable(tag reflect.StructTag) bool {
+	_, ok := tag.Lookup("yeditable")
+	return ok
+}
+
+// EnumListOptions gets the list of options from a `yenum` tag, for use with
+// the autoconfig.EnumList type.
+func EnumListOptions(yenum string) []string {
+	return strings.Split(yenum, `;;`) // Same separator as Qt filter (yfilter)
+}
+
+// matchOption finds the index of the option, preferring an exact match and
+// then a case-insensitive match.
+func matchOption(opts []string, s string) (int, bool) {
+	for i, opt := range opts {
+		if opt == s {
+			return i, true
+		}
+	}
+	for i, opt := range opts {
+		if strings.EqualFold(opt, s) {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,51 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testEnumConfig struct {
+	Profiles []string
+	Active   string `yenum:"test-profiles"`
+	Device   string `yenum:"test-devices"`
+	Unknown  string `yenum:"test-unregistered"`
+}
+
+func (c *testEnumConfig) EnumOptions(field string) ([]string, bool) {
+	if field == "Active" {
+		return c.Profiles, true
+	}
+	return nil, false
+}
+
+func TestEnumStringOptionsFor(t *testing.T) {
+	SetEnumStringOptions("test-devices", []string{"static"})
+	defer SetEnumStringOptions("test-devices", nil)
+
+	cfg := testEnumConfig{Profiles: []string{"home", "work"}}
+	owner := reflect.ValueOf(&cfg).Elem()
+	lookup := func(field string) ([]string, bool) {
+		ff, _ := owner.Type().FieldByName(field)
+		return EnumStringOptionsFor(owner, field, ff.Tag)
+	}
+
+	if got, ok := lookup("Active"); !ok || !reflect.DeepEqual(got, cfg.Profiles) {
+		t.Errorf("EnumProvider: got %q, %v", got, ok)
+	}
+	if got, ok := lookup("Device"); !ok || !reflect.DeepEqual(got, []string{"static"}) {
+		t.Errorf("SetEnumStringOptions: got %q, %v", got, ok)
+	}
+
+	SetEnumStringFunc("test-devices", func(parent any) []string {
+		return []string{"scanned", parent.(*testEnumConfig).Profiles[0]}
+	})
+	defer SetEnumStringFunc("test-devices", nil)
+
+	if got, ok := lookup("Device"); !ok || !reflect.DeepEqual(got, []string{"scanned", "home"}) {
+		t.Errorf("SetEnumStringFunc: got %q, %v", got, ok)
+	}
+	if _, ok := lookup("Unknown"); ok {
+		t.Errorf("Unknown: expected no options")
+	}
+}
@@ -0,0 +1,136 @@
+package schema
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Factor is a single unit for a Factor-based type. The stored integer value is
+// the displayed value multiplied by the Divisor.
+type Factor struct {
+	Divisor int64
+	Label   string
+}
+
+// ParseFactorTag parses the pairs from a `yfactor` struct tag.
+// It panics if the tag is malformed, as this is a programmer error.
+func ParseFactorTag(yfactor string) []Factor {
+	parts := strings.Split(yfactor, `;;`)
+	if len(parts)%2 != 0 {
+		panic("autoconfig.Factor expects yfactor to have an even number of properties") // Programmer error
+	}
+
+	factors := make([]Factor, 0, len(parts)/2)
+
+	for i := 0; i < len(parts); i += 2 {
+		parse, err := strconv.ParseInt(parts[i], 10, 64)
+		if err != nil {
+			panic(err) // Programmer error
+		}
+
+		factors = append(factors, Factor{parse, parts[i+1]})
+	}
+
+	return factors
+}
+
+var bytesFactors = []Factor{
+	{1, "B"},
+	{1024, "KiB"},
+	{1024 * 1024, "MiB"},
+	{1024 * 1024 * 1024, "GiB"},
+	{1024 * 1024 * 1024 * 1024, "TiB"},
+	{1024 * 1024 * 1024 * 1024 * 1024, "PiB"},
+}
+
+var metricBytesFactors = []Factor{
+	{1, "B"},
+	{1000, "KB"},
+	{1000 * 1000, "MB"},
+	{1000 * 1000 * 1000, "GB"},
+	{1000 * 1000 * 1000 * 1000, "TB"},
+	{1000 * 1000 * 1000 * 1000 * 1000, "PB"},
+}
+
+var bitrateFactors = []Factor{
+	{1, "bit"},
+	{8, "B/s"},
+	{1 * 1024, "Kbit"},
+	{8 * 1024, "KB/s"},
+	{1 * 1024 * 1024, "MBit"},
+	{8 * 1024 * 1024, "MB/s"},
+	{1 * 1024 * 1024 * 1024, "GBit"},
+	{8 * 1024 * 1024 * 1024, "GB/s"},
+}
+
+// Distance units, in microns
+const (
+	micron     = 1
+	millimeter = 1000
+	centimeter = millimeter * 10
+	meter      = centimeter * 100
+	kilometer  = meter * 1000
+
+	inch = micron * 25400
+	foot = inch * 12
+	yard = foot * 3
+	mile = foot * 5280
+)
+
+var distanceFactors = []Factor{
+	{micron, "microns"},
+	{millimeter, "mm"},
+	{centimeter, "cm"},
+	{inch, "inches"},
+	{foot, "feet"},
+	{yard, "yards"},
+	{meter, "m"},
+	{kilometer, "km"},
+	{mile, "miles"},
+}
+
+var durationFactors = []Factor{
+	{int64(time.Nanosecond), "nsec"},  // x1
+	{int64(time.Microsecond), "μsec"}, // x1000
+	{int64(time.Millisecond), "ms"},   // x1000 x1000
+	{int64(time.Second), "seconds"},   // x1000 x1000 x1000
+	{int64(time.Minute), "minutes"},   // x1000 x1000 x1000 x60
+	{int64(time.Hour), "hours"},       // x1000 x1000 x1000 x60 x60
+}
+
+// Factors gets the list of units used to render the type, if it is a
+// Factor-based type (Factor with a `yfactor` tag, Bytes, MetricBytes, Bitrate,
+// Distance, or time.Duration).
+func Factors(t reflect.Type, tag reflect.StructTag) ([]Factor, bool) {
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		return durationFactors, true
+	case isRootType(t, "Bytes"):
+		return bytesFactors, true
+	case isRootType(t, "MetricBytes"):
+		return metricBytesFactors, true
+	case isRootType(t, "Bitrate"):
+		return bitrateFactors, true
+	case isRootType(t, "Distance"):
+		return distanceFactors, true
+	case isRootType(t, "Factor"):
+		if yfactor := tag.Get("yfactor"); len(yfactor) > 0 {
+			return ParseFactorTag(yfactor), true
+		}
+	}
+
+	return nil, false
+}
+
+// InitialFactor picks the right-most unit that divides the value without any
+// remainder, in the same way as the Factor renderer.
+func InitialFactor(factors []Factor, val int64) int {
+	for i := len(factors) - 1; i >= 0; i-- {
+		if val%factors[i].Divisor == 0 {
+			return i
+		}
+	}
+	return 0
+}
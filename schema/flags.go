@@ -0,0 +1,114 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Flag is a single named bit for a Flags-based type. The mask may also have
+// several bits, for a combination of other flags.
+type Flag struct {
+	Mask  uint64
+	Label string
+}
+
+// ParseFlagsTag parses the pairs from a `yflags` struct tag, in the same
+// syntax as `yfactor`. Masks may be decimal or hexadecimal (0x prefix).
+// It panics if the tag is malformed, as this is a programmer error.
+func ParseFlagsTag(yflags string) []Flag {
+	parts := strings.Split(yflags, `;;`)
+	if len(parts)%2 != 0 {
+		panic("autoconfig.Flags expects yflags to have an even number of properties") // Programmer error
+	}
+
+	flags := make([]Flag, 0, len(parts)/2)
+
+	for i := 0; i < len(parts); i += 2 {
+		mask, err := strconv.ParseUint(parts[i], 0, 64)
+		if err != nil {
+			panic(err) // Programmer error
+		}
+
+		flags = append(flags, Flag{mask, parts[i+1]})
+	}
+
+	return flags
+}
+
+// Flags gets the named bits for an unsigned integer type with a `yflags` tag,
+// including autoconfig.Flags. It returns false if the type has no named bits.
+func Flags(t reflect.Type, tag reflect.StructTag) ([]Flag, bool) {
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if yflags := tag.Get("yflags"); len(yflags) > 0 {
+			return ParseFlagsTag(yflags), true
+		}
+	}
+
+	return nil, false
+}
+
+// FlagsMask gets all the bits that have a name.
+func FlagsMask(flags []Flag) uint64 {
+	var ret uint64
+	for _, fl := range flags {
+		ret |= fl.Mask
+	}
+	return ret
+}
+
+// FormatFlags summarises the value as the labels of the flags that are set,
+// e.g. "Read|Write". Any other bits are shown in hexadecimal, e.g.
+// "Read|0x40". A value with no bits set is "0", or the label of a zero flag.
+func FormatFlags(flags []Flag, val uint64) string {
+	var parts []string
+	remaining := val
+	for _, fl := range flags {
+		if fl.Mask == 0 {
+			if val == 0 {
+				return fl.Label
+			}
+			continue
+		}
+		if remaining&fl.Mask == fl.Mask {
+			parts = append(parts, fl.Label)
+			remaining &^= fl.Mask
+		}
+	}
+
+	if remaining != 0 {
+		parts = append(parts, fmt.Sprintf("%#x", remaining))
+	}
+	if len(parts) == 0 {
+		return "0"
+	}
+	return strings.Join(parts, "|")
+}
+
+// parseFlagsText parses flag labels or numbers, separated by "|" or ",".
+func parseFlagsText(flags []Flag, s string) (uint64, error) {
+	labels := make([]string, 0, len(flags))
+	for _, fl := range flags {
+		labels = append(labels, fl.Label)
+	}
+
+	var ret uint64
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '|' || r == ',' }) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		if idx, ok := matchOption(labels, part); ok {
+			ret |= flags[idx].Mask
+		} else if bits, err := strconv.ParseUint(part, 0, 64); err == nil {
+			ret |= bits
+		} else {
+			return 0, fmt.Errorf("invalid flag %q, expected any of: %s", part, strings.Join(labels, ", "))
+		}
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,48 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlags(t *testing.T) {
+	type testPermissions struct {
+		Mode uint32 `yflags:"0;;None;;1;;Read;;2;;Write;;0x4;;Execute"`
+	}
+
+	ff := reflect.TypeOf(testPermissions{}).Field(0)
+	if kind := Classify(ff.Type, ff.Tag); kind != KindFlags {
+		t.Fatalf("Classify: got %v", kind)
+	}
+
+	var mode uint32
+	rv := reflect.ValueOf(&mode).Elem()
+
+	cases := []struct {
+		input  string
+		expect uint32
+		format string
+	}{
+		{"Read|Write", 3, "Read|Write"},
+		{"execute, read", 5, "Read|Execute"},
+		{"Read|0x40", 0x41, "Read|0x40"},
+		{"", 0, "None"},
+		{"None", 0, "None"},
+		{"6", 6, "Write|Execute"},
+	}
+	for _, tc := range cases {
+		if err := ParseText(rv, ff.Tag, tc.input); err != nil || mode != tc.expect {
+			t.Errorf("ParseText(%q): got %d, %v", tc.input, mode, err)
+		}
+		if got := FormatText(rv, ff.Tag); got != tc.format {
+			t.Errorf("FormatText(%d): got %q, want %q", mode, got, tc.format)
+		}
+	}
+
+	if err := ParseText(rv, ff.Tag, "Read|Delete"); err == nil {
+		t.Errorf("ParseText: expected error for unknown flag")
+	}
+	if err := ParseText(rv, ff.Tag, "0x100000000"); err == nil {
+		t.Errorf("ParseText: expected error for overflow")
+	}
+}
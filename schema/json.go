@@ -0,0 +1,35 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+)
+
+// JSONName gets the serialized name of a struct field, using the same rules as
+// encoding/json.
+// It returns false if the field should be skipped.
+func JSONName(ff reflect.StructField) (string, bool) {
+	if !ff.IsExported() {
+		return "", false
+	}
+
+	return jsonName(ff.Name, ff.Tag)
+}
+
+func jsonName(name string, tag reflect.StructTag) (string, bool) {
+	jsonTag, ok := tag.Lookup("json")
+	if !ok {
+		return name, true
+	}
+
+	if jsonTag == "-" {
+		return "", false
+	}
+
+	useName, _, _ := strings.Cut(jsonTag, ",")
+	if useName == "" {
+		return name, true
+	}
+
+	return useName, true
+}
@@ -0,0 +1,306 @@
+package schema
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSONSchemaDialect is the "$schema" URI of the generated JSON Schema.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema builds a JSON Schema (draft 2020-12) for the encoding/json
+// representation of a configurable struct type, following the same rules as
+// the Qt renderer:
+//   - `ylabel` (or the field name) is used as the title, and `yhelp` as the
+//     description
+//   - EnumList and EnumString are enums
+//   - OneOf structs use "oneOf", with the selector field as a discriminator.
+//     An empty selector is allowed, for the zero value with no option chosen
+//   - Factor types, including time.Duration, are integers, with the available
+//     units in the "x-units" annotation
+//   - Flags are integers, with the named bits in the "x-flags" annotation
+//   - Integer sizes and the `ymin`/`ymax` tags are used as minimum and maximum
+//   - Pointers, slices and maps are nullable
+//
+// Fields that can't be represented in JSON (e.g. funcs, channels, complex
+// numbers) are omitted. Recursive types are placed in "$defs".
+//
+// The result can be passed to json.Marshal.
+func JSONSchema(t reflect.Type) map[string]any {
+	g := jsonSchemaGen{
+		structs: make(map[reflect.Type]map[string]any),
+		names:   make(map[reflect.Type]string),
+		used:    make(map[string]bool),
+	}
+
+	ret := g.schemaFor(DescribeType(t).Root)
+	ret["$schema"] = JSONSchemaDialect
+
+	if len(g.names) > 0 {
+		defs := make(map[string]any)
+		for dt, name := range g.names {
+			defs[name] = g.structs[dt]
+		}
+		ret["$defs"] = defs
+	}
+
+	return ret
+}
+
+type jsonSchemaGen struct {
+	structs map[reflect.Type]map[string]any // First (outermost) schema for each struct type
+	names   map[reflect.Type]string         // $defs names for recursive types
+	used    map[string]bool
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schemaFor gets the schema for a field's type, or nil if it can't be
+// represented in JSON.
+func (g *jsonSchemaGen) schemaFor(f *Field) map[string]any {
+	switch f.Kind {
+	case KindFixed, KindHeader, KindComplex:
+		return nil
+
+	case KindBool:
+		return map[string]any{"type": "boolean"}
+
+	case KindString, KindExistingFile, KindExistingDirectory, KindMultiLineString:
+		return map[string]any{"type": "string"}
+
+	case KindPassword:
+		return map[string]any{"type": "string", "format": "password", "writeOnly": true}
+
+	case KindInt:
+		min, max := IntBounds(f.Type, f.Tag)
+		return map[string]any{"type": "integer", "minimum": min, "maximum": max}
+
+	case KindUint:
+		min, max := UintBounds(f.Type, f.Tag)
+		return map[string]any{"type": "integer", "minimum": min, "maximum": max}
+
+	case KindFlags:
+		var flags []any
+		for _, fl := range f.Flags {
+			flags = append(flags, map[string]any{"name": fl.Label, "mask": fl.Mask})
+		}
+		min, max := UintBounds(f.Type, f.Tag)
+		return map[string]any{"type": "integer", "minimum": min, "maximum": max, "x-flags": flags}
+
+	case KindFloat:
+		ret := map[string]any{"type": "number"}
+		if HasBounds(f.Tag) {
+			min, max := FloatBounds(f.Tag)
+			ret["minimum"] = min
+			ret["maximum"] = max
+		}
+		return ret
+
+	case KindBytes:
+		return map[string]any{"type": []string{"string", "null"}, "contentEncoding": "base64"}
+
+	case KindTime:
+		return map[string]any{"type": "string", "format": "date-time"}
+
+	case KindDuration, KindFactor:
+		var units []any
+		for _, fac := range f.Factors {
+			units = append(units, map[string]any{"name": fac.Label, "multiplier": fac.Divisor})
+		}
+		return map[string]any{"type": "integer", "x-units": units}
+
+	case KindEnumList:
+		var options []any
+		for i, opt := range f.EnumOptions {
+			options = append(options, map[string]any{"const": i, "title": opt})
+		}
+		return map[string]any{"type": "integer", "oneOf": options}
+
+	case KindEnumString:
+		ret := map[string]any{"type": "string"}
+		if opts, ok := EnumStringOptions(f.Tag.Get("yenum")); ok && EnumEditable(f.Tag) {
+			ret["examples"] = opts
+		} else if ok {
+			ret["enum"] = opts
+		}
+		return ret
+
+	case KindAddressPort:
+		return map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"Address": map[string]any{"type": "string"},
+				"Port":    map[string]any{"type": "integer", "minimum": 0, "maximum": 65535},
+			},
+		}
+
+	case KindCustom:
+		pt := reflect.PointerTo(f.Type)
+		if !pt.Implements(jsonMarshalerType) && pt.Implements(textMarshalerType) {
+			return map[string]any{"type": "string"}
+		}
+		return map[string]any{} // Unknown
+
+	case KindStruct, KindTabGroup, KindGroupBox, KindOneOf:
+		return g.structSchema(f)
+
+	case KindPointer:
+		elem := g.schemaFor(f.Elem)
+		if elem == nil {
+			return nil
+		}
+		return nullable(elem)
+
+	case KindSlice, KindArray:
+		items := g.schemaFor(f.Elem)
+		if items == nil {
+			return nil
+		}
+		if f.Kind == KindArray {
+			return map[string]any{"type": "array", "items": items, "minItems": f.Type.Len(), "maxItems": f.Type.Len()}
+		}
+		return map[string]any{"type": []string{"array", "null"}, "items": items}
+
+	case KindMap:
+		values := g.schemaFor(f.Elem)
+		if values == nil {
+			return nil
+		}
+		return map[string]any{"type": []string{"object", "null"}, "additionalProperties": values}
+	}
+
+	return nil
+}
+
+func (g *jsonSchemaGen) structSchema(f *Field) map[string]any {
+	if f.Recursive {
+		return map[string]any{"$ref": "#/$defs/" + g.defName(f.Type)}
+	}
+
+	props := make(map[string]any)
+	ret := map[string]any{"type": "object", "properties": props}
+	if _, ok := g.structs[f.Type]; !ok {
+		g.structs[f.Type] = ret
+	}
+
+	if f.Kind == KindOneOf {
+		// The selector is a discriminator for the options
+		selector, ok := JSONName(f.Type.Field(0))
+		if !ok {
+			return ret
+		}
+
+		// The zero value has an empty selector, and no option
+		names := []string{""}
+		options := []any{map[string]any{
+			"title":      "None",
+			"properties": map[string]any{selector: map[string]any{"const": ""}},
+		}}
+		for _, option := range f.Children {
+			name, ok := jsonName(option.Name, option.Tag)
+			if !ok {
+				continue
+			}
+			names = append(names, option.Name)
+			options = append(options, map[string]any{
+				"title":      option.Label,
+				"properties": map[string]any{selector: map[string]any{"const": option.Name}},
+				"required":   []string{selector, name},
+			})
+		}
+
+		props[selector] = map[string]any{"type": "string", "enum": names}
+		ret["oneOf"] = options
+	}
+
+	g.properties(f, props)
+	return ret
+}
+
+// properties adds the properties of a struct, flattening embedded structs in
+// the same way as encoding/json.
+func (g *jsonSchemaGen) properties(f *Field, props map[string]any) {
+	for _, child := range f.Children {
+		name, ok := jsonName(child.Name, child.Tag)
+		if !ok {
+			continue
+		}
+
+		if tagName, _, _ := strings.Cut(child.Tag.Get("json"), ","); child.Embedded && tagName == "" {
+			g.properties(child, props)
+			continue
+		}
+
+		prop := g.schemaFor(child)
+		if prop == nil {
+			continue
+		}
+		props[name] = annotate(prop, child)
+	}
+}
+
+// defName gets a unique name for a recursive type in "$defs".
+func (g *jsonSchemaGen) defName(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	base := t.Name()
+	if base == "" {
+		base = "Type"
+	}
+
+	name := base
+	for i := 2; g.used[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+
+	g.names[t] = name
+	g.used[name] = true
+	return name
+}
+
+// annotate adds the field's label and help text to a copy of the schema.
+func annotate(schema map[string]any, f *Field) map[string]any {
+	ret := make(map[string]any, len(schema)+2)
+	for k, v := range schema {
+		ret[k] = v
+	}
+
+	if f.Label != "" {
+		ret["title"] = f.Label
+	}
+	if f.Help != "" {
+		ret["description"] = f.Help
+	}
+	return ret
+}
+
+// nullable allows the schema to also match null. A schema with "oneOf" is
+// wrapped in "anyOf" instead, as null would otherwise match every option.
+func nullable(schema map[string]any) map[string]any {
+	if _, ok := schema["oneOf"]; ok {
+		return map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+	}
+
+	if t, ok := schema["type"].(string); ok {
+		ret := make(map[string]any, len(schema))
+		for k, v := range schema {
+			ret[k] = v
+		}
+		ret["type"] = []string{t, "null"}
+		return ret
+	}
+
+	if _, ok := schema["type"]; ok {
+		return schema // Already a list, which includes null
+	}
+
+	return map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+}
@@ -0,0 +1,85 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testJSONTree struct {
+	Name     string
+	Children []testJSONTree
+}
+
+type testJSONStruct struct {
+	Common
+	Port     uint16  `json:"port" ylabel:"Listen port" yhelp:"TCP port"`
+	Ratio    float64 `ymin:"0" ymax:"1"`
+	Level    int8    `ymin:"-5"`
+	Timeout  time.Duration
+	Optional *struct {
+		Enabled bool
+	}
+	Secret   string `json:"-"`
+	Tree     testJSONTree
+	Callback func()
+}
+
+func TestJSONSchema(t *testing.T) {
+	s := JSONSchema(reflect.TypeOf(testJSONStruct{}))
+
+	// Round-trip through JSON to compare with plain values
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+
+	if got["$schema"] != JSONSchemaDialect || got["type"] != "object" {
+		t.Errorf("root: got %v", got)
+	}
+
+	props := got["properties"].(map[string]any)
+
+	expect := map[string]any{
+		"Shared": map[string]any{"type": "integer", "title": "Shared", "minimum": float64(-9223372036854775808), "maximum": float64(9223372036854775807)},
+		"port":   map[string]any{"type": "integer", "title": "Listen port", "description": "TCP port", "minimum": float64(0), "maximum": float64(65535)},
+		"Ratio":  map[string]any{"type": "number", "title": "Ratio", "minimum": float64(0), "maximum": float64(1)},
+		"Level":  map[string]any{"type": "integer", "title": "Level", "minimum": float64(-5), "maximum": float64(127)},
+	}
+	for name, want := range expect {
+		if !reflect.DeepEqual(props[name], want) {
+			t.Errorf("%s: got %v, want %v", name, props[name], want)
+		}
+	}
+
+	if _, ok := props["Secret"]; ok {
+		t.Errorf("Secret: expected json:\"-\" to be skipped")
+	}
+	if _, ok := props["Callback"]; ok {
+		t.Errorf("Callback: expected func to be skipped")
+	}
+
+	timeout := props["Timeout"].(map[string]any)
+	if units := timeout["x-units"].([]any); timeout["type"] != "integer" || len(units) != 6 {
+		t.Errorf("Timeout: got %v", timeout)
+	}
+
+	optional := props["Optional"].(map[string]any)
+	if !reflect.DeepEqual(optional["type"], []any{"object", "null"}) {
+		t.Errorf("Optional: expected nullable object, got %v", optional)
+	}
+
+	// Recursive types are referenced from $defs
+	children := props["Tree"].(map[string]any)["properties"].(map[string]any)["Children"].(map[string]any)
+	if ref := children["items"].(map[string]any)["$ref"]; ref != "#/$defs/testJSONTree" {
+		t.Errorf("Tree.Children: got %v", children)
+	}
+	if _, ok := got["$defs"].(map[string]any)["testJSONTree"]; !ok {
+		t.Errorf("$defs: got %v", got["$defs"])
+	}
+}
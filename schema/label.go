@@ -0,0 +1,84 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Label gets the display label for a struct field, from the `ylabel` tag if
+// present, or else from the field's name. Any mnemonic is removed.
+func Label(ff reflect.StructField) string {
+	return StripMnemonic(MnemonicLabel(ff))
+}
+
+// MnemonicLabel gets the display label for a struct field, keeping any "&"
+// before the mnemonic character in the `ylabel` tag (e.g. "&Port"). Use "&&"
+// for a literal ampersand.
+func MnemonicLabel(ff reflect.StructField) string {
+	if useLabel, ok := ff.Tag.Lookup("ylabel"); ok { // Explicit name
+		return useLabel
+	}
+
+	// Automatic name: field value with _ as spaces
+	return FormatLabel(ff.Name)
+}
+
+// StripMnemonic removes the "&" before a mnemonic character from a label, and
+// replaces "&&" with a literal ampersand.
+func StripMnemonic(label string) string {
+	if !strings.Contains(label, "&") {
+		return label
+	}
+
+	var ret strings.Builder
+	escaped := false
+	for _, ch := range label {
+		if ch == '&' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		ret.WriteRune(ch)
+	}
+	return ret.String()
+}
+
+// FormatLabel tries to generate a nice label from the automatic struct field.
+func FormatLabel(s string) string {
+	// Mode: convert _ as spaces
+	if strings.Contains(s, `_`) {
+		return strings.TrimSpace(strings.ReplaceAll(s, `_`, ` `))
+	}
+
+	// Mode: convert CamelCase to spaces
+	// Handle embedded acronyms (e.g. "TLSConfig" -> "TLS Config")
+	var ret string
+	var hold string // single previous uppercase character
+	for _, ch := range s {
+		if string(ch) == strings.ToUpper(string(ch)) {
+			// uppercase
+			if len(hold) == 0 && len(ret) > 0 && ret[len(ret)-1] != ' ' {
+				// first uppercase after a previous lowercase = new word
+				ret += " "
+			}
+
+			ret += hold
+			hold = string(ch)
+		} else {
+			// lowercase
+			if len(hold) > 0 {
+				// first lowercase after a previous uppercase
+				if len(ret) > 0 && ret[len(ret)-1] != ' ' {
+					ret += " "
+				}
+				ret += hold
+				hold = ""
+			}
+			ret += string(ch)
+		}
+	}
+	if len(hold) > 0 {
+		ret += hold
+	}
+	return ret
+}
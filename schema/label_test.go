@@ -0,0 +1,85 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormatLabel(t *testing.T) {
+	type testCase struct {
+		input, expect string
+	}
+
+	cases := []testCase{
+		// Simple case
+		{"foo", "foo"},
+		{"Foo", "Foo"},
+
+		// Underscore style
+		{"Foo_Bar", "Foo Bar"},
+		{"__Foo_Bar__", "Foo Bar"},
+
+		// Camelcase style
+		{"FooBar", "Foo Bar"},
+		{"FoBa", "Fo Ba"},
+		{"FoB", "Fo B"},
+		{"FBa", "F Ba"},
+		{"FB", "FB"},
+		{"TLSConfig", "TLS Config"},
+		{"ConfigTLS", "Config TLS"},
+		{"PrefixACRONYMSuffix", "Prefix ACRONYM Suffix"},
+	}
+
+	for _, tc := range cases {
+		got := FormatLabel(tc.input)
+		if got != tc.expect {
+			t.Errorf("FormatLabel(%q): got %q, want %q", tc.input, got, tc.expect)
+		}
+	}
+}
+
+func TestStripMnemonic(t *testing.T) {
+	cases := map[string]string{
+		"&Port":          "Port",
+		"Listen &port":   "Listen port",
+		"Save && Exit":   "Save & Exit",
+		"&Save && &Exit": "Save & Exit",
+		"Trailing&":      "Trailing",
+		"Plain":          "Plain",
+	}
+
+	for input, expect := range cases {
+		if got := StripMnemonic(input); got != expect {
+			t.Errorf("StripMnemonic(%q): got %q, want %q", input, got, expect)
+		}
+	}
+}
+
+type testTranslator map[string]string
+
+func (tt testTranslator) Translate(context, text string) string {
+	return tt[context+"|"+text]
+}
+
+func TestTranslate(t *testing.T) {
+	type testStruct struct {
+		ListenPort int `ylabel:"Port" yhelp:"TCP port" ygroup:"Network"`
+	}
+
+	SetTranslator(testTranslator{
+		"schema.testStruct.ListenPort|Port":     "Anschluss",
+		"schema.testStruct.ListenPort|TCP port": "TCP-Anschluss",
+		"schema.testStruct|Network":             "Netzwerk",
+	})
+	defer SetTranslator(nil)
+
+	f := StructFields(reflect.TypeOf(testStruct{}))[0]
+	if f.Label != "Anschluss" || f.Help != "TCP-Anschluss" || f.Group != "Netzwerk" {
+		t.Errorf("StructFields: got %q, %q, %q", f.Label, f.Help, f.Group)
+	}
+
+	// Missing translations use the source text
+	if got := Translate(ContextUnit, "KiB"); got != "KiB" {
+		t.Errorf("Translate: got %q", got)
+	}
+}
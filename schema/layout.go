@@ -0,0 +1,107 @@
+package schema
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// LayoutOption changes which fields are described, for Describe, StructFields
+// and FormFields.
+type LayoutOption func(*layoutOptions)
+
+type layoutOptions struct {
+	hideJSONIgnored bool
+}
+
+func makeLayoutOptions(opts []LayoutOption) *layoutOptions {
+	ret := &layoutOptions{}
+	for _, opt := range opts {
+		opt(ret)
+	}
+	return ret
+}
+
+// HideJSONIgnored hides fields with a `json:"-"` tag, in the same way as the
+// `yhide` tag.
+func HideJSONIgnored() LayoutOption {
+	return func(o *layoutOptions) {
+		o.hideJSONIgnored = true
+	}
+}
+
+// order gets the display order from a `yorder` tag.
+// It panics if the tag is malformed, as this is a programmer error.
+func order(tag reflect.StructTag) int {
+	yorder, ok := tag.Lookup("yorder")
+	if !ok {
+		return 0
+	}
+
+	ret, err := strconv.Atoi(yorder)
+	if err != nil {
+		panic(err) // Programmer error
+	}
+	return ret
+}
+
+// FormFields describes the fields to show in a form for a struct type, in
+// display order. Unlike StructFields, the fields of embedded structs are
+// promoted into the list, so that they can be interleaved with the parent's
+// fields. Embedded OneOf, TabGroup and GroupBox structs are kept whole.
+//
+// The fields are sorted by their `yorder` tag (default 0), and otherwise keep
+// their declaration order. Promoted fields without their own `yorder` or
+// `ygroup` tag use the tag of the embedded struct.
+//
+// Use IndexPath to find each field's value, as Index is only the index within
+// the embedded struct.
+func FormFields(t reflect.Type, opts ...LayoutOption) []*Field {
+	o := makeLayoutOptions(opts)
+	return formFields(structFields(t, o), func(f *Field) []*Field {
+		return structFields(f.Type, o)
+	})
+}
+
+// FormFields gets the field's described children in display order, in the
+// same way as the FormFields function.
+func (f *Field) FormFields() []*Field {
+	return formFields(f.Children, func(f *Field) []*Field {
+		return f.Children
+	})
+}
+
+func formFields(fields []*Field, children func(f *Field) []*Field) []*Field {
+	var ret []*Field
+	promote(&ret, fields, nil, nil, children)
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Order < ret[j].Order
+	})
+	return ret
+}
+
+// promote adds copies of the fields to the list, with their IndexPath set, and
+// with the fields of embedded structs in place of the struct itself.
+func promote(ret *[]*Field, fields []*Field, parent *Field, path []int, children func(f *Field) []*Field) {
+	for _, f := range fields {
+		cp := *f
+		cp.IndexPath = append(append([]int(nil), path...), f.Index)
+
+		if parent != nil {
+			if _, ok := f.Tag.Lookup("yorder"); !ok {
+				cp.Order = parent.Order
+			}
+			if cp.Group == "" {
+				cp.Group = parent.Group
+			}
+		}
+
+		if f.Embedded && f.Kind == KindStruct && !f.Recursive {
+			promote(ret, children(f), &cp, cp.IndexPath, children)
+			continue
+		}
+
+		*ret = append(*ret, &cp)
+	}
+}
@@ -0,0 +1,68 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SelectOneOf sets the OneOf struct to the option with the given field index,
+// clearing all other options, in the same way as the OneOf renderer.
+func SelectOneOf(rv reflect.Value, idx int) {
+	obj := rv.Type()
+	rv.Field(0).SetString(obj.Field(idx).Name)
+	for i := 1; i < obj.NumField(); i++ {
+		if i != idx {
+			rv.Field(i).SetZero()
+		}
+	}
+}
+
+// SelectOneOfText selects a OneOf option by its field name or label. If the
+// selected option is a nil pointer, it is allocated, and reset if it has a
+// Reset() method.
+func SelectOneOfText(rv reflect.Value, s string) error {
+	obj := rv.Type()
+
+	var names, labels []string
+	for i := 1; i < obj.NumField(); i++ {
+		names = append(names, obj.Field(i).Name)
+		labels = append(labels, Label(obj.Field(i)))
+	}
+
+	idx, ok := matchOption(names, s)
+	if !ok {
+		idx, ok = matchOption(labels, s)
+	}
+	if !ok {
+		return fmt.Errorf("invalid option %q, expected one of: %s", s, strings.Join(names, ", "))
+	}
+
+	SelectOneOf(rv, idx+1)
+
+	opt := rv.Field(idx + 1)
+	if opt.Kind() == reflect.Pointer && opt.IsNil() {
+		Allocate(opt)
+	}
+
+	return nil
+}
+
+// OneOfOptions lists the field names of all options in a OneOf struct type.
+func OneOfOptions(obj reflect.Type) []string {
+	var names []string
+	for i := 1; i < obj.NumField(); i++ {
+		names = append(names, obj.Field(i).Name)
+	}
+	return names
+}
+
+// Allocate sets a nil pointer to a new value. If the new value has a Reset()
+// method (see autoconfig.Resetter), it is called.
+func Allocate(ptr reflect.Value) {
+	ptr.Set(reflect.New(ptr.Type().Elem()))
+
+	if defaulter, ok := ptr.Interface().(interface{ Reset() }); ok {
+		defaulter.Reset()
+	}
+}
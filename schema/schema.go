@@ -0,0 +1,474 @@
+package schema
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RootPackage is the import path of the autoconfig package. Types from this
+// package are recognised by name.
+const RootPackage = "github.com/mappu/autoconfig"
+
+// Kind is the way that a field is presented.
+type Kind int
+
+const (
+	KindFixed             Kind = iota // Can't be configured (func, chan, interface)
+	KindBool                          // bool
+	KindString                        // string
+	KindInt                           // Signed integer
+	KindUint                          // Unsigned integer
+	KindFloat                         // float32, float64
+	KindComplex                       // complex64, complex128
+	KindStruct                        // Child struct, see Children
+	KindOneOf                         // Struct with a OneOf selector, see Children for the options
+	KindTabGroup                      // Struct with a TabGroup marker, see Children for the tabs
+	KindGroupBox                      // Struct with a GroupBox marker, see Children
+	KindSlice                         // Slice, see Elem
+	KindArray                         // Fixed-size array, see Elem
+	KindMap                           // Map, see Key and Elem
+	KindPointer                       // Optional value, see Elem
+	KindBytes                         // []byte
+	KindTime                          // time.Time
+	KindDuration                      // time.Duration, see Factors
+	KindFactor                        // Integer with units, see Factors
+	KindEnumList                      // autoconfig.EnumList, see EnumOptions
+	KindEnumString                    // autoconfig.EnumString, see EnumOptions
+	KindFlags                         // Unsigned integer with named bits, see Flags
+	KindAddressPort                   // autoconfig.AddressPort
+	KindPassword                      // autoconfig.Password, or a string named like a password
+	KindExistingFile                  // autoconfig.ExistingFile
+	KindExistingDirectory             // autoconfig.ExistingDirectory, or a string named like a directory
+	KindMultiLineString               // autoconfig.MultiLineString
+	KindHeader                        // autoconfig.Header
+	KindCustom                        // Some other type with its own Render method
+)
+
+var kindNames = []string{
+	"Fixed", "Bool", "String", "Int", "Uint", "Float", "Complex", "Struct",
+	"OneOf", "TabGroup", "GroupBox", "Slice", "Array", "Map", "Pointer", "Bytes", "Time",
+	"Duration", "Factor", "EnumList", "EnumString", "Flags", "AddressPort", "Password",
+	"ExistingFile", "ExistingDirectory", "MultiLineString", "Header", "Custom",
+}
+
+func (k Kind) String() string {
+	if k >= 0 && int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Field describes a single configurable value.
+type Field struct {
+	Name     string            // Struct field name, or "" for the root and for elements
+	Path     string            // Dotted path from the root, see Schema.Lookup
+	Index    int               // Index in the parent struct, or -1
+	Kind     Kind              // How the value is presented
+	Type     reflect.Type      // Go type of the value
+	Tag      reflect.StructTag // Struct tag, or "" for the root and for elements
+	Label    string            // Display label, from `ylabel` or the field name
+	Mnemonic string            // Display label with any "&" mnemonic marker, see MnemonicLabel
+	Help     string            // From the `yhelp` tag
+	Icon     string            // From the `yicon` tag
+	Group    string            // From the `ygroup` tag
+	Order    int               // From the `yorder` tag, see FormFields
+	Embedded bool              // Embedded struct, rendered inline with its parent
+	Secret   bool              // The value should not be displayed
+
+	EnumOptions []string // For KindEnumList and KindEnumString, if known
+	Factors     []Factor // For KindFactor and KindDuration
+	Flags       []Flag   // For KindFlags
+
+	Children  []*Field // For KindStruct, KindOneOf, KindTabGroup and KindGroupBox
+	Elem      *Field   // For KindPointer, KindSlice, KindArray and KindMap
+	Key       *Field   // For KindMap
+	Recursive bool     // The type refers to one of its parents, so Children is not populated
+
+	IndexPath []int // From FormFields, the index sequence for reflect.Value.FieldByIndex
+
+	layout *layoutOptions // For describing the children, see Expand
+}
+
+// Schema is a tree of field descriptors for a configurable struct.
+type Schema struct {
+	Type reflect.Type
+	Root *Field
+}
+
+// Describe builds the schema for a configurable struct, or a pointer to one.
+func Describe(ct any, opts ...LayoutOption) *Schema {
+	return DescribeType(reflect.TypeOf(ct), opts...)
+}
+
+// DescribeType builds the schema for a type.
+func DescribeType(t reflect.Type, opts ...LayoutOption) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	root := &Field{Index: -1, Kind: Classify(t, ""), Type: t, Label: "Configure", Mnemonic: "Configure", layout: makeLayoutOptions(opts)}
+	describe(root, nil)
+
+	return &Schema{Type: t, Root: root}
+}
+
+// Expand gets a copy of a recursive field with its children described, with
+// the same options as the schema. Other fields are returned unchanged.
+func (f *Field) Expand() *Field {
+	if !f.Recursive {
+		return f
+	}
+
+	ret := *f
+	ret.Recursive = false
+	ret.Children = nil
+	describe(&ret, nil)
+	return &ret
+}
+
+// Lookup finds a field by its path, e.g. "Network.ListenPort".
+//
+// Embedded structs and pointers don't add a path segment. Slice and array
+// elements are found with a "[]" suffix, and map keys and values with "{}"
+// and "[]" suffixes, e.g. "Peers[]" or "Labels{}".
+func (s *Schema) Lookup(path string) *Field {
+	var ret *Field
+	s.Walk(func(f *Field) bool {
+		if ret == nil && f.Path == path && !f.Embedded {
+			ret = f
+		}
+		return ret == nil
+	})
+	return ret
+}
+
+// Walk visits every field in the schema depth-first, starting at the root.
+// If the function returns false, the field's descendants are skipped.
+func (s *Schema) Walk(fn func(f *Field) bool) {
+	walk(s.Root, fn)
+}
+
+func walk(f *Field, fn func(f *Field) bool) {
+	if !fn(f) {
+		return
+	}
+	if f.Key != nil {
+		walk(f.Key, fn)
+	}
+	if f.Elem != nil {
+		walk(f.Elem, fn)
+	}
+	for _, child := range f.Children {
+		walk(child, fn)
+	}
+}
+
+// StructFields describes the direct fields of a struct type, without
+// descending into them. Private fields are skipped, as are the OneOf, TabGroup
+// and GroupBox marker fields, and fields with the `yhide` tag. Fields with a
+// `json:"-"` tag are also skipped, if enabled with HideJSONIgnored.
+//
+// The fields are in declaration order. Use FormFields to get them in display
+// order instead.
+//
+// String fields named like SomethingDir are described as KindExistingDirectory,
+// and string fields named like SomethingPass or SomethingPassword are described
+// as KindPassword.
+func StructFields(t reflect.Type, opts ...LayoutOption) []*Field {
+	return structFields(t, makeLayoutOptions(opts))
+}
+
+func structFields(t reflect.Type, o *layoutOptions) []*Field {
+	if o == nil {
+		o = &layoutOptions{} // e.g. for a Field that was not from Describe
+	}
+
+	var ret []*Field
+
+	nf := t.NumField()
+	for i := 0; i < nf; i++ {
+		ff := t.Field(i)
+
+		if i == 0 && (isRootType(ff.Type, "OneOf") || isRootType(ff.Type, "TabGroup") || isRootType(ff.Type, "GroupBox")) {
+			continue
+		}
+
+		// Don't show private fields
+		if !ff.IsExported() {
+			continue
+		}
+
+		if _, ok := ff.Tag.Lookup("yhide"); ok {
+			continue
+		}
+		if o.hideJSONIgnored && ff.Tag.Get("json") == "-" {
+			continue
+		}
+
+		f := &Field{
+			Name:     ff.Name,
+			Index:    i,
+			Kind:     Classify(ff.Type, ff.Tag),
+			Type:     ff.Type,
+			Tag:      ff.Tag,
+			Label:    FieldLabel(t, ff),
+			Mnemonic: FieldMnemonicLabel(t, ff),
+			Help:     Translate(FieldContext(t, ff.Name), ff.Tag.Get("yhelp")),
+			Icon:     ff.Tag.Get("yicon"),
+			Group:    Translate(t.String(), ff.Tag.Get("ygroup")),
+			Order:    order(ff.Tag),
+			Secret:   isSecret(ff),
+			layout:   o,
+		}
+
+		if ff.Type == reflect.TypeOf("") {
+			// Heuristics for plain strings
+			if strings.HasSuffix(ff.Name, `Dir`) {
+				f.Kind = KindExistingDirectory
+			} else if f.Secret {
+				f.Kind = KindPassword
+			}
+		}
+
+		f.Embedded = ff.Anonymous && (f.Kind == KindStruct || f.Kind == KindTabGroup || f.Kind == KindGroupBox || f.Kind == KindOneOf)
+
+		ret = append(ret, f)
+	}
+
+	return ret
+}
+
+// isSecret checks if the struct field is a Password, or is a string field
+// that should be treated as if it were a Password.
+func isSecret(ff reflect.StructField) bool {
+	t := ff.Type
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if isRootType(t, "Password") {
+		return true
+	}
+
+	return t == reflect.TypeOf("") && (strings.HasSuffix(ff.Name, `Pass`) || strings.HasSuffix(ff.Name, `Password`))
+}
+
+// Classify gets the Kind of a type, in the same order of precedence as the Qt
+// renderer. The tag is used to decide if an autoconfig.Factor has any units,
+// and if an unsigned integer has named bits.
+func Classify(t reflect.Type, tag reflect.StructTag) Kind {
+
+	if t.Kind() == reflect.Pointer {
+		return KindPointer
+	}
+
+	if t.PkgPath() == RootPackage {
+		switch t.Name() {
+		case "AddressPort":
+			return KindAddressPort
+		case "Bytes", "MetricBytes", "Bitrate", "Distance":
+			return KindFactor
+		case "Factor":
+			if tag.Get("yfactor") == "" {
+				return KindInt // Factor without yfactor tag is just an int64
+			}
+			return KindFactor
+		case "EnumList":
+			return KindEnumList
+		case "EnumString":
+			return KindEnumString
+		case "Flags":
+			if tag.Get("yflags") == "" {
+				return KindUint // Flags without yflags tag is just a uint64
+			}
+			return KindFlags
+		case "ExistingDirectory":
+			return KindExistingDirectory
+		case "ExistingFile":
+			return KindExistingFile
+		case "Header":
+			return KindHeader
+		case "MultiLineString":
+			return KindMultiLineString
+		case "Password":
+			return KindPassword
+		}
+	}
+
+	if hasRenderMethod(t) {
+		return KindCustom
+	}
+
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return KindTime
+	case reflect.TypeOf(time.Duration(0)):
+		return KindDuration
+	case reflect.TypeOf([]byte{}):
+		return KindBytes
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return KindBool
+	case reflect.String:
+		return KindString
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return KindInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if tag.Get("yflags") != "" {
+			return KindFlags
+		}
+		return KindUint
+	case reflect.Float32, reflect.Float64:
+		return KindFloat
+	case reflect.Complex64, reflect.Complex128:
+		return KindComplex
+	case reflect.Struct:
+		if t.NumField() > 0 {
+			if first := t.Field(0).Type; isRootType(first, "OneOf") {
+				return KindOneOf
+			} else if isRootType(first, "TabGroup") {
+				return KindTabGroup
+			} else if isRootType(first, "GroupBox") {
+				return KindGroupBox
+			}
+		}
+		return KindStruct
+	case reflect.Slice:
+		return KindSlice
+	case reflect.Array:
+		return KindArray
+	case reflect.Map:
+		return KindMap
+	default:
+		// Func, Chan, UnsafePointer, Interface
+		return KindFixed
+	}
+}
+
+// hasRenderMethod checks if the type has a custom renderer, with either a value
+// or a pointer receiver. Only a Render method with the autoconfig.Renderer
+// signature counts, so an unrelated Render method is ignored.
+func hasRenderMethod(t reflect.Type) bool {
+	if m, ok := t.MethodByName("Render"); ok && isRenderSignature(m.Type) {
+		return true
+	}
+	m, ok := reflect.PointerTo(t).MethodByName("Render")
+	return ok && isRenderSignature(m.Type)
+}
+
+// isRenderSignature checks a method type (including its receiver) against
+// Render(*qt.QFormLayout, *reflect.Value, reflect.StructTag, string) SaveFunc.
+// The qt package is matched by name, so that this package doesn't import it.
+func isRenderSignature(mt reflect.Type) bool {
+	if mt.NumIn() != 5 || mt.NumOut() != 1 {
+		return false
+	}
+	area := mt.In(1)
+	if area.Kind() != reflect.Pointer || area.Elem().Name() != "QFormLayout" {
+		return false
+	}
+	if mt.In(2) != reflect.TypeOf((*reflect.Value)(nil)) ||
+		mt.In(3) != reflect.TypeOf(reflect.StructTag("")) ||
+		mt.In(4) != reflect.TypeOf("") {
+		return false
+	}
+	return isRootType(mt.Out(0), "SaveFunc")
+}
+
+// isRootType checks if the type is the named type from the autoconfig package.
+func isRootType(t reflect.Type, name string) bool {
+	return t.PkgPath() == RootPackage && t.Name() == name
+}
+
+// describe fills in the details of a field, and any descendants. The parents
+// list is used to detect recursive types.
+func describe(f *Field, parents []reflect.Type) {
+
+	switch f.Kind {
+	case KindEnumList:
+		if yenum, ok := f.Tag.Lookup("yenum"); ok {
+			f.EnumOptions = EnumListOptions(yenum)
+		}
+
+	case KindEnumString:
+		f.EnumOptions, _ = EnumStringOptions(f.Tag.Get("yenum"))
+
+	case KindFactor, KindDuration:
+		f.Factors, _ = Factors(f.Type, f.Tag)
+
+	case KindFlags:
+		f.Flags, _ = Flags(f.Type, f.Tag)
+
+	case KindStruct, KindOneOf, KindTabGroup, KindGroupBox:
+		for _, parent := range parents {
+			if parent == f.Type {
+				f.Recursive = true
+				return
+			}
+		}
+		parents = append(parents, f.Type)
+
+		for _, child := range structFields(f.Type, f.layout) {
+			if child.Embedded {
+				child.Path = f.Path
+			} else {
+				child.Path = JoinPath(f.Path, child.Name)
+			}
+			describe(child, parents)
+			f.Children = append(f.Children, child)
+		}
+
+	case KindPointer:
+		f.Elem = element(f, f.Type.Elem(), f.Path)
+		describe(f.Elem, parents)
+
+	case KindSlice, KindArray:
+		f.Elem = element(f, f.Type.Elem(), f.Path+"[]")
+		describe(f.Elem, parents)
+
+	case KindMap:
+		f.Key = element(f, f.Type.Key(), f.Path+"{}")
+		describe(f.Key, parents)
+		f.Elem = element(f, f.Type.Elem(), f.Path+"[]")
+		describe(f.Elem, parents)
+	}
+}
+
+// element creates the descriptor for the element of a pointer, slice, array or
+// map. Elements share the tag of their container.
+func element(parent *Field, t reflect.Type, path string) *Field {
+	ret := &Field{
+		Path:     path,
+		Index:    -1,
+		Kind:     Classify(t, parent.Tag),
+		Type:     t,
+		Tag:      parent.Tag,
+		Label:    parent.Label,
+		Mnemonic: parent.Mnemonic,
+		Help:     parent.Help,
+		Icon:     parent.Icon,
+		Secret:   parent.Secret,
+		layout:   parent.layout,
+	}
+
+	if parent.Kind == KindPointer {
+		// Pointers are transparent
+		ret.Name = parent.Name
+		ret.Index = parent.Index
+	}
+
+	return ret
+}
+
+// JoinPath gets the path of a struct field, from the path of its parent, see
+// Schema.Lookup.
+func JoinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
@@ -0,0 +1,280 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testNode struct {
+	Name     string
+	Children []testNode
+	Parent   *testNode
+}
+
+type Common struct {
+	Shared int
+}
+
+type testSchemaStruct struct {
+	Common
+	ListenPort int    `ylabel:"Port" yhelp:"TCP port to listen on"`
+	DataDir    string // Heuristic
+	AdminPass  string // Heuristic
+	Timeout    time.Duration
+	Network    struct {
+		Enabled bool
+		Peers   []string
+	}
+	Labels map[string]float64
+	Tree   testNode
+	Hook   func()
+	hidden int
+}
+
+func TestDescribe(t *testing.T) {
+	s := Describe(&testSchemaStruct{})
+
+	if s.Type != reflect.TypeOf(testSchemaStruct{}) || s.Root.Kind != KindStruct {
+		t.Fatalf("root: got %v %v", s.Type, s.Root.Kind)
+	}
+
+	type testCase struct {
+		path  string
+		kind  Kind
+		label string
+	}
+
+	cases := []testCase{
+		{"Shared", KindInt, "Shared"},
+		{"ListenPort", KindInt, "Port"},
+		{"DataDir", KindExistingDirectory, "Data Dir"},
+		{"AdminPass", KindPassword, "Admin Pass"},
+		{"Timeout", KindDuration, "Timeout"},
+		{"Network", KindStruct, "Network"},
+		{"Network.Enabled", KindBool, "Enabled"},
+		{"Network.Peers", KindSlice, "Peers"},
+		{"Network.Peers[]", KindString, "Peers"},
+		{"Labels", KindMap, "Labels"},
+		{"Labels{}", KindString, "Labels"},
+		{"Labels[]", KindFloat, "Labels"},
+		{"Tree.Children[]", KindStruct, "Children"},
+		{"Hook", KindFixed, "Hook"},
+	}
+
+	for _, tc := range cases {
+		f := s.Lookup(tc.path)
+		if f == nil {
+			t.Errorf("Lookup(%q): not found", tc.path)
+			continue
+		}
+		if f.Kind != tc.kind || f.Label != tc.label {
+			t.Errorf("Lookup(%q): got %v %q, want %v %q", tc.path, f.Kind, f.Label, tc.kind, tc.label)
+		}
+	}
+
+	if s.Lookup("hidden") != nil {
+		t.Errorf("Lookup(hidden): private fields should be skipped")
+	}
+
+	if f := s.Lookup("ListenPort"); f.Help != "TCP port to listen on" || f.Index != 1 {
+		t.Errorf("ListenPort: got help %q index %d", f.Help, f.Index)
+	}
+
+	if f := s.Lookup("Timeout"); len(f.Factors) == 0 || f.Factors[len(f.Factors)-1].Label != "hours" {
+		t.Errorf("Timeout: got factors %v", f.Factors)
+	}
+
+	if f := s.Lookup("AdminPass"); !f.Secret {
+		t.Errorf("AdminPass: expected secret")
+	}
+
+	// Recursive types stop at the first repeat
+	if f := s.Lookup("Tree.Children[]"); !f.Recursive || len(f.Children) != 0 {
+		t.Errorf("Tree.Children[]: expected recursive, got %#v", f)
+	}
+	if f := s.Lookup("Tree.Parent"); f.Kind != KindPointer || !f.Elem.Recursive {
+		t.Errorf("Tree.Parent: expected recursive pointer, got %#v", f)
+	}
+}
+
+// testRenderName has a Render method that isn't a form renderer.
+type testRenderName string
+
+func (n testRenderName) Render() string { return "<" + string(n) + ">" }
+
+type testRenderStruct struct {
+	Count int
+}
+
+func (s *testRenderStruct) Render(prefix string) string { return prefix }
+
+func TestClassifyUnrelatedRender(t *testing.T) {
+	if k := Classify(reflect.TypeOf(testRenderName("")), ""); k != KindString {
+		t.Errorf("string with Render(): expected KindString, got %v", k)
+	}
+	if k := Classify(reflect.TypeOf(testRenderStruct{}), ""); k != KindStruct {
+		t.Errorf("struct with Render(string): expected KindStruct, got %v", k)
+	}
+}
+
+func TestText(t *testing.T) {
+	var d time.Duration
+	rv := reflect.ValueOf(&d).Elem()
+
+	for _, input := range []string{"90 minutes", "1h30m", "5400000000000"} {
+		if err := ParseText(rv, "", input); err != nil || d != 90*time.Minute {
+			t.Errorf("ParseText(%q): got %v, %v", input, d, err)
+		}
+	}
+
+	if got := FormatText(rv, ""); got != "1h30m0s" {
+		t.Errorf("FormatText: got %q", got)
+	}
+
+	var i int
+	rv = reflect.ValueOf(&i).Elem()
+	if err := ParseText(rv, "", "seven"); err == nil {
+		t.Errorf("ParseText: expected error for invalid int")
+	}
+
+	rv = reflect.ValueOf(&d).Elem()
+	if err := ParseText(rv, "", "9999999999 hours"); err == nil {
+		t.Errorf("ParseText: expected error for overflow")
+	}
+}
+
+func TestClone(t *testing.T) {
+	type inner struct {
+		Values []int
+	}
+	type outer struct {
+		Ptr    *inner
+		Map    map[string]*inner
+		Array  [2]inner
+		hidden *int
+	}
+
+	n := 5
+	orig := outer{
+		Ptr:    &inner{Values: []int{1, 2}},
+		Map:    map[string]*inner{"a": {Values: []int{3}}},
+		Array:  [2]inner{{Values: []int{4}}},
+		hidden: &n,
+	}
+
+	clone := Clone(reflect.ValueOf(orig)).Interface().(outer)
+	if !reflect.DeepEqual(orig, clone) {
+		t.Fatalf("Clone: got %#v", clone)
+	}
+
+	clone.Ptr.Values[0] = 100
+	clone.Map["a"].Values[0] = 100
+	clone.Array[0].Values[0] = 100
+
+	if orig.Ptr.Values[0] != 1 || orig.Map["a"].Values[0] != 3 || orig.Array[0].Values[0] != 4 {
+		t.Errorf("Clone: original was modified, got %#v", orig)
+	}
+	if clone.hidden != orig.hidden {
+		t.Errorf("Clone: expected unexported fields to be shared")
+	}
+}
+
+func TestCloneCycle(t *testing.T) {
+	root := &testNode{Name: "root"}
+	root.Children = []testNode{{Name: "child", Parent: root}}
+
+	clone := Clone(reflect.ValueOf(root)).Interface().(*testNode)
+	if clone == root || clone.Children[0].Parent != clone {
+		t.Errorf("Clone: expected the back-pointer to point to the copy")
+	}
+
+	clone.Children[0].Name = "renamed"
+	if changes := Diff(reflect.ValueOf(root), reflect.ValueOf(clone)); len(changes) != 1 || changes[0].Path != "Children[0].Name" {
+		t.Errorf("Diff: got %v", changes)
+	}
+
+	dst := &testNode{}
+	Assign(reflect.ValueOf(dst).Elem(), reflect.ValueOf(root).Elem())
+	if dst.Name != "root" || dst.Children[0].Parent != dst {
+		t.Errorf("Assign: expected the back-pointer to point to dst, got %#v", dst)
+	}
+}
+
+func TestAssign(t *testing.T) {
+	type inner struct {
+		Values []int
+	}
+	type outer struct {
+		Ptr   *inner
+		Other *inner
+		List  []inner
+		Map   map[string]int
+	}
+
+	dst := outer{Ptr: &inner{}, Other: &inner{}, List: []inner{{}}}
+	ptr, elem := dst.Ptr, &dst.List[0]
+
+	src := outer{
+		Ptr:  &inner{Values: []int{1, 2}},
+		List: []inner{{Values: []int{3}}},
+		Map:  map[string]int{"a": 1},
+	}
+	Assign(reflect.ValueOf(&dst).Elem(), reflect.ValueOf(src))
+
+	if !reflect.DeepEqual(dst, src) {
+		t.Fatalf("Assign: got %#v", dst)
+	}
+	if dst.Ptr != ptr || &dst.List[0] != elem {
+		t.Errorf("Assign: expected the existing pointer and slice to be kept")
+	}
+
+	dst.Ptr.Values[0] = 100
+	dst.Map["a"] = 100
+	if src.Ptr.Values[0] != 1 || src.Map["a"] != 1 {
+		t.Errorf("Assign: source was modified, got %#v", src)
+	}
+}
+
+type FormBase struct {
+	Host  string
+	Extra string `yorder:"2"`
+}
+
+type testFormStruct struct {
+	FormBase `ygroup:"Server"`
+	Name     string `yorder:"-1"`
+	Port     int
+	Internal string `yhide:""`
+	Cache    string `json:"-"`
+}
+
+func TestFormFields(t *testing.T) {
+	fields := FormFields(reflect.TypeOf(testFormStruct{}))
+
+	var got []string
+	for _, f := range fields {
+		got = append(got, f.Name+"/"+f.Group)
+	}
+
+	expect := []string{"Name/", "Host/Server", "Port/", "Cache/", "Extra/Server"}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("FormFields: got %v, want %v", got, expect)
+	}
+
+	if !reflect.DeepEqual(fields[1].IndexPath, []int{0, 0}) {
+		t.Errorf("Host: got IndexPath %v", fields[1].IndexPath)
+	}
+
+	// Described fields are in the same order
+	if len(Describe(&testFormStruct{}).Root.FormFields()) != len(fields) {
+		t.Errorf("Field.FormFields: got a different length")
+	}
+
+	if n := len(FormFields(reflect.TypeOf(testFormStruct{}), HideJSONIgnored())); n != 4 {
+		t.Errorf("HideJSONIgnored: got %d fields", n)
+	}
+	if n := len(Describe(&testFormStruct{}, HideJSONIgnored()).Root.FormFields()); n != 4 {
+		t.Errorf("Describe with HideJSONIgnored: got %d fields", n)
+	}
+}
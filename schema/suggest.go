@@ -0,0 +1,64 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Suggester is implemented by a field's type, or by the struct that has the
+// field, to suggest values for a free-text field, e.g. known hostnames or
+// locale codes. The user may still enter any other value.
+//
+// Suggest gets the suggestions for the field at the dotted path, e.g.
+// "Network.Host". The last element of the path is the field name.
+type Suggester interface {
+	Suggest(path string) []string
+}
+
+// Suggestions gets the suggested values for a text field, without duplicates,
+// in order from:
+//   - The Suggester of the owner, the struct that has the field (if valid)
+//   - The Suggester of the field's value
+//   - The field's `ysuggest` tag, separated by double-semicolon (`;;`)
+func Suggestions(owner reflect.Value, rv reflect.Value, tag reflect.StructTag, path string) []string {
+	var ret []string
+	seen := map[string]bool{}
+	add := func(opts []string) {
+		for _, opt := range opts {
+			if opt != "" && !seen[opt] {
+				seen[opt] = true
+				ret = append(ret, opt)
+			}
+		}
+	}
+
+	if s, ok := suggester(owner); ok {
+		add(s.Suggest(path))
+	}
+	if s, ok := suggester(rv); ok {
+		add(s.Suggest(path))
+	}
+	if ysuggest, ok := tag.Lookup("ysuggest"); ok {
+		add(strings.Split(ysuggest, `;;`))
+	}
+
+	return ret
+}
+
+// suggester gets the Suggester of the value, with either value or pointer
+// receiver.
+func suggester(rv reflect.Value) (Suggester, bool) {
+	if !rv.IsValid() {
+		return nil, false
+	}
+	if rv.CanAddr() {
+		if s, ok := rv.Addr().Interface().(Suggester); ok {
+			return s, true
+		}
+	}
+	if rv.CanInterface() {
+		s, ok := rv.Interface().(Suggester)
+		return s, ok
+	}
+	return nil, false
+}
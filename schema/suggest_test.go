@@ -0,0 +1,41 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testHostname string
+
+func (testHostname) Suggest(path string) []string {
+	return []string{"localhost", "example.com"}
+}
+
+type testSuggestConfig struct {
+	Host   testHostname `ysuggest:"example.com;;example.org"`
+	Locale string       `ysuggest:"en_US;;de_DE"`
+}
+
+func (c *testSuggestConfig) Suggest(path string) []string {
+	if path == "Network.Host" {
+		return []string{"intranet"}
+	}
+	return nil
+}
+
+func TestSuggestions(t *testing.T) {
+	var cfg testSuggestConfig
+	owner := reflect.ValueOf(&cfg).Elem()
+
+	got := Suggestions(owner, owner.Field(0), owner.Type().Field(0).Tag, "Network.Host")
+	expect := []string{"intranet", "localhost", "example.com", "example.org"}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Host: got %q, want %q", got, expect)
+	}
+
+	got = Suggestions(reflect.Value{}, owner.Field(1), owner.Type().Field(1).Tag, "Locale")
+	expect = []string{"en_US", "de_DE"}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Locale: got %q, want %q", got, expect)
+	}
+}
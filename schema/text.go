@@ -0,0 +1,303 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IsText checks if the type can be converted to and from a single string with
+// ParseText and FormatText.
+func IsText(t reflect.Type) bool {
+	switch t {
+	case reflect.TypeOf(time.Time{}), reflect.TypeOf([]byte{}):
+		return true
+	}
+	if isRootType(t, "AddressPort") {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+
+	return false
+}
+
+// ParseText parses a string into the value, following the same rules as the
+// GUI renderer for the type:
+//   - Factor types accept a unit suffix (e.g. "10MiB", "5 minutes")
+//   - time.Duration also accepts Go duration syntax (e.g. "1h30m")
+//   - EnumList accepts an option name, or its numeric index
+//   - EnumString accepts any registered option
+//   - Flags accept labels or numbers separated by "|" or "," (e.g. "Read|Write")
+//   - AddressPort accepts "host:port"
+//   - time.Time accepts RFC3339
+//   - Numbers are checked against the `ymin` and `ymax` tags
+//
+// Use ParseTextFor instead if the struct that has the field is known, so that
+// EnumString options from an EnumProvider or SetEnumStringFunc are used.
+func ParseText(rv reflect.Value, tag reflect.StructTag, s string) error {
+	return ParseTextFor(reflect.Value{}, "", rv, tag, s)
+}
+
+// ParseTextFor parses a string into the value in the same way as ParseText.
+// The owner is the struct that has the field, if valid, and field is the name
+// of the field in it. They are passed to EnumStringOptionsFor.
+func ParseTextFor(owner reflect.Value, field string, rv reflect.Value, tag reflect.StructTag, s string) error {
+
+	if factors, ok := Factors(rv.Type(), tag); ok {
+		if rv.Type() == reflect.TypeOf(time.Duration(0)) {
+			if d, err := time.ParseDuration(s); err == nil {
+				rv.SetInt(int64(d))
+				return nil
+			}
+		}
+
+		val, err := parseFactorText(factors, s)
+		if err != nil {
+			return err
+		}
+		rv.SetInt(val)
+		return nil
+	}
+
+	if flags, ok := Flags(rv.Type(), tag); ok {
+		val, err := parseFlagsText(flags, s)
+		if err != nil {
+			return err
+		}
+		if rv.OverflowUint(val) {
+			return fmt.Errorf("value %#x is out of range for %s", val, rv.Type())
+		}
+		rv.SetUint(val)
+		return nil
+	}
+
+	switch {
+	case rv.Type() == reflect.TypeOf(time.Time{}):
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(t))
+		return nil
+
+	case rv.Type() == reflect.TypeOf([]byte{}):
+		rv.SetBytes([]byte(s))
+		return nil
+
+	case isRootType(rv.Type(), "AddressPort"):
+		rv.SetZero()
+		if s == "" {
+			return nil
+		}
+		host, portStr, err := net.SplitHostPort(s)
+		if err != nil {
+			return err
+		}
+		port, err := strconv.ParseUint(portStr, 10, 16)
+		if err != nil {
+			return fmt.Errorf("invalid port %q", portStr)
+		}
+		rv.FieldByName("Address").SetString(host)
+		rv.FieldByName("Port").SetInt(int64(port))
+		return nil
+
+	case isRootType(rv.Type(), "EnumList"):
+		opts := EnumListOptions(tag.Get("yenum"))
+		if idx, ok := matchOption(opts, s); ok {
+			rv.SetInt(int64(idx))
+			return nil
+		}
+		if idx, err := strconv.Atoi(s); err == nil && idx >= 0 && idx < len(opts) {
+			rv.SetInt(int64(idx))
+			return nil
+		}
+		return fmt.Errorf("invalid option %q, expected one of: %s", s, strings.Join(opts, ", "))
+
+	case isRootType(rv.Type(), "EnumString"):
+		opts, ok := EnumStringOptionsFor(owner, field, tag)
+		if !ok {
+			rv.SetString(s) // Can't validate
+			return nil
+		}
+		idx, ok := matchOption(opts, s)
+		if !ok && EnumEditable(tag) {
+			rv.SetString(s) // The options are only suggestions
+			return nil
+		} else if !ok {
+			return fmt.Errorf("invalid option %q, expected one of: %s", s, strings.Join(opts, ", "))
+		}
+		rv.SetString(opts[idx])
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		rv.SetBool(b)
+
+	case reflect.String:
+		rv.SetString(s)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 0, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		if min, max := IntBounds(rv.Type(), tag); i < min || i > max {
+			return fmt.Errorf("value %d is out of range %d to %d", i, min, max)
+		}
+		rv.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(s, 0, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		if min, max := UintBounds(rv.Type(), tag); u < min || u > max {
+			return fmt.Errorf("value %d is out of range %d to %d", u, min, max)
+		}
+		rv.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		if min, max := FloatBounds(tag); f < min || f > max {
+			return fmt.Errorf("value %g is out of range %g to %g", f, min, max)
+		}
+		rv.SetFloat(f)
+
+	case reflect.Complex64, reflect.Complex128:
+		c, err := strconv.ParseComplex(s, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetComplex(c)
+
+	default:
+		return errors.New("unsupported type " + rv.Type().String())
+	}
+
+	return nil
+}
+
+// FormatText formats the value as a string that can be read back by ParseText.
+func FormatText(rv reflect.Value, tag reflect.StructTag) string {
+
+	if factors, ok := Factors(rv.Type(), tag); ok {
+		if rv.Type() == reflect.TypeOf(time.Duration(0)) {
+			return time.Duration(rv.Int()).String()
+		}
+		return formatFactorText(factors, rv.Int())
+	}
+
+	if flags, ok := Flags(rv.Type(), tag); ok {
+		return FormatFlags(flags, rv.Uint())
+	}
+
+	switch {
+	case rv.Type() == reflect.TypeOf(time.Time{}):
+		return rv.Interface().(time.Time).Format(time.RFC3339)
+
+	case rv.Type() == reflect.TypeOf([]byte{}):
+		return string(rv.Bytes())
+
+	case isRootType(rv.Type(), "AddressPort"):
+		if rv.IsZero() {
+			return ""
+		}
+		return net.JoinHostPort(rv.FieldByName("Address").String(), strconv.FormatInt(rv.FieldByName("Port").Int(), 10))
+
+	case isRootType(rv.Type(), "EnumList"):
+		opts := EnumListOptions(tag.Get("yenum"))
+		if idx := int(rv.Int()); idx >= 0 && idx < len(opts) {
+			return opts[idx]
+		}
+		return strconv.FormatInt(rv.Int(), 10)
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool())
+	case reflect.String:
+		return rv.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, rv.Type().Bits())
+	case reflect.Complex64, reflect.Complex128:
+		return strconv.FormatComplex(rv.Complex(), 'g', -1, rv.Type().Bits())
+	}
+
+	return fmt.Sprint(rv.Interface())
+}
+
+// parseFactorText parses an integer with an optional unit suffix.
+func parseFactorText(factors []Factor, s string) (int64, error) {
+	s = strings.TrimSpace(s)
+
+	numEnd := 0
+	for numEnd < len(s) && (s[numEnd] == '-' || s[numEnd] == '+' || (s[numEnd] >= '0' && s[numEnd] <= '9')) {
+		numEnd++
+	}
+
+	num, err := strconv.ParseInt(s[:numEnd], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+
+	unit := strings.TrimSpace(s[numEnd:])
+	if unit == "" {
+		return num, nil // Raw value
+	}
+
+	labels := make([]string, 0, len(factors))
+	for _, fac := range factors {
+		labels = append(labels, fac.Label)
+	}
+
+	idx, ok := matchOption(labels, unit)
+	if !ok {
+		return 0, fmt.Errorf("invalid unit %q, expected one of: %s", unit, strings.Join(labels, ", "))
+	}
+
+	div := factors[idx].Divisor
+	val := num * div
+	if div != 0 && val/div != num {
+		return 0, fmt.Errorf("value %q is out of range", s)
+	}
+	return val, nil
+}
+
+// formatFactorText formats the value using the largest unit that divides it
+// without any remainder, in the same way as the Factor renderer.
+func formatFactorText(factors []Factor, val int64) string {
+	if val == 0 {
+		return "0"
+	}
+
+	for i := len(factors) - 1; i >= 0; i-- {
+		if val%factors[i].Divisor == 0 {
+			return strconv.FormatInt(val/factors[i].Divisor, 10) + factors[i].Label
+		}
+	}
+
+	return strconv.FormatInt(val, 10)
+}
@@ -0,0 +1,62 @@
+package schema
+
+import (
+	"reflect"
+)
+
+// Translator translates the text shown in a form.
+//
+// The context identifies where the text is used, in the same way as Qt's
+// QCoreApplication::translate. Field labels and `yhelp` text use the struct
+// type and field name (see FieldContext), `ygroup` names use the struct type,
+// and other text uses one of the Context constants. The text is the English
+// source text.
+type Translator interface {
+	Translate(context, text string) string
+}
+
+const (
+	ContextUI   = "autoconfig"      // Built-in text, e.g. button labels and "Not configured"
+	ContextEnum = "autoconfig.enum" // Options from EnumList `yenum` tags and SetEnumStringOptions
+	ContextUnit = "autoconfig.unit" // Factor unit names, e.g. "KiB" or "minutes"
+)
+
+var translator Translator
+
+// SetTranslator configures the translator for all text shown in a form, or
+// nil to show the English source text.
+// This is package-global and not threadsafe.
+func SetTranslator(t Translator) {
+	translator = t
+}
+
+// Translate translates the text with the current translator, if any. The
+// source text is used if there is no translation.
+func Translate(context, text string) string {
+	if translator == nil || text == "" {
+		return text
+	}
+
+	if ret := translator.Translate(context, text); ret != "" {
+		return ret
+	}
+	return text
+}
+
+// FieldContext gets the translation context for a struct field's label and
+// help text, e.g. "main.Config.ListenPort".
+func FieldContext(t reflect.Type, name string) string {
+	return t.String() + "." + name
+}
+
+// FieldLabel gets the translated label of a field of the struct type, without
+// any mnemonic.
+func FieldLabel(t reflect.Type, ff reflect.StructField) string {
+	return StripMnemonic(FieldMnemonicLabel(t, ff))
+}
+
+// FieldMnemonicLabel gets the translated label of a field of the struct type,
+// keeping any mnemonic. The source text for translation includes the "&".
+func FieldMnemonicLabel(t reflect.Type, ff reflect.StructField) string {
+	return Translate(FieldContext(t, ff.Name), MnemonicLabel(ff))
+}
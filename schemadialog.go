@@ -0,0 +1,789 @@
+package autoconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mappu/autoconfig/schema"
+	qt "github.com/mappu/miqt/qt6"
+)
+
+// OpenSchemaDialog opens a JSON document for editing in a new modal dialog.
+// The form is built from a JSON Schema instead of a Go type, so that any JSON
+// document can be edited.
+//
+// Objects are shown as nested forms, enums as dropdowns, arrays and maps with
+// the usual list editors, "oneOf" alternatives with the OneOf picker, and
+// nullable values as optional fields. Anything else is edited as JSON text.
+// Properties in the document that are not in the schema are kept unchanged.
+//
+// As with OpenDialog, changes are saved into the document when the dialog is
+// closed. If any value edited as JSON text is not valid JSON, the error is
+// shown and the dialog is opened again, without changing the document.
+// It returns an error if the schema does not describe an object.
+func OpenSchemaDialog(jsonSchema map[string]any, doc *map[string]any, parent *qt.QWidget, title string, onFinished func(), opts ...Option) error {
+	node, rv, err := newSchemaValue(jsonSchema, *doc)
+	if err != nil {
+		return err
+	}
+
+	var open func()
+	open = func() {
+		OpenDialog(rv.Interface(), parent, title, func() {
+			ret, err := node.toJSON(rv.Elem(), *doc)
+			if err != nil {
+				qt.QMessageBox_Warning(parent, tr("Invalid JSON"), err.Error())
+				open() // Keep the changes, so they can be fixed
+				return
+			}
+			*doc = ret.(map[string]any)
+			onFinished()
+		}, opts...)
+	}
+	open()
+
+	return nil
+}
+
+// newSchemaValue builds a Go type for the JSON Schema, and a new value of that
+// type holding the document.
+func newSchemaValue(jsonSchema map[string]any, doc map[string]any) (*schemaNode, reflect.Value, error) {
+	b := schemaBuilder{root: jsonSchema, active: make(map[string]bool)}
+	node := b.build(jsonSchema)
+	if node.kind != schemaObject && node.kind != schemaOneOf {
+		return nil, reflect.Value{}, errors.New("OpenSchemaDialog: the schema must describe an object")
+	}
+
+	rv := reflect.New(node.typ)
+	node.fromJSON(rv.Elem(), doc)
+	return node, rv, nil
+}
+
+type schemaNodeKind int
+
+const (
+	schemaJSON     schemaNodeKind = iota // Anything else, edited as JSON text
+	schemaObject                         // Struct
+	schemaMap                            // map[string]T
+	schemaArray                          // []T
+	schemaNullable                       // *T
+	schemaOneOf                          // Struct with OneOf
+	schemaEnum                           // EnumList
+	schemaString                         // string, or Password
+	schemaInteger                        // int64, or Factor
+	schemaNumber                         // float64
+	schemaBoolean                        // bool
+)
+
+// schemaNode describes the Go type that is used for a JSON Schema, and how to
+// convert JSON values to and from that type.
+type schemaNode struct {
+	kind    schemaNodeKind
+	typ     reflect.Type
+	fields  []schemaNodeField // For schemaObject, in struct field order
+	consts  map[string]any    // For schemaObject, properties with a fixed value
+	elem    *schemaNode       // For schemaMap, schemaArray and schemaNullable
+	enum    []any             // For schemaEnum
+	labels  []string          // For schemaEnum
+	options []*schemaNode     // For schemaOneOf, in struct field order after the OneOf
+	def     any               // From the "default" keyword, or nil
+}
+
+type schemaNodeField struct {
+	name string // JSON property name
+	node *schemaNode
+}
+
+type schemaBuilder struct {
+	root   map[string]any
+	active map[string]bool // $ref being built, to detect recursion
+}
+
+var jsonTextNode = &schemaNode{kind: schemaJSON, typ: reflect.TypeOf(MultiLineString(""))}
+
+func (b *schemaBuilder) build(s any) *schemaNode {
+	m, ok := s.(map[string]any)
+	if !ok {
+		return jsonTextNode // e.g. true
+	}
+
+	if ref, ok := m["$ref"].(string); ok {
+		if b.active[ref] {
+			return jsonTextNode // Recursive types can't be built with reflect.StructOf
+		}
+		target, ok := b.resolve(ref)
+		if !ok {
+			return jsonTextNode
+		}
+		b.active[ref] = true
+		defer delete(b.active, ref)
+		return b.build(target)
+	}
+
+	ret := b.buildType(m)
+	ret.def = m["default"]
+	return ret
+}
+
+// resolve finds a local reference, e.g. "#/$defs/Name".
+func (b *schemaBuilder) resolve(ref string) (map[string]any, bool) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, false
+	}
+
+	var current any = b.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current = m[part]
+	}
+
+	ret, ok := current.(map[string]any)
+	return ret, ok
+}
+
+func (b *schemaBuilder) buildType(m map[string]any) *schemaNode {
+
+	if enum, ok := m["enum"].([]any); ok && len(enum) > 0 {
+		var labels []string
+		for _, v := range enum {
+			labels = append(labels, enumLabel(v, nil))
+		}
+		return enumNode(enum, labels)
+	}
+
+	alternatives, _ := m["oneOf"].([]any)
+	if alternatives == nil {
+		alternatives, _ = m["anyOf"].([]any)
+	}
+	if len(alternatives) > 0 {
+		return b.buildAlternatives(m, alternatives)
+	}
+
+	var types []string
+	nullable := false
+	switch t := m["type"].(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, v := range t {
+			if s, _ := v.(string); s == "null" {
+				nullable = true
+			} else {
+				types = append(types, s)
+			}
+		}
+	}
+
+	var ret *schemaNode
+	switch {
+	case len(types) == 0 && m["properties"] != nil:
+		ret = b.buildObject(m, nil, nil)
+	case len(types) != 1:
+		return jsonTextNode
+	default:
+		ret = b.buildSingleType(m, types[0])
+	}
+
+	if nullable && ret.kind != schemaJSON {
+		return nullableNode(ret)
+	}
+	return ret
+}
+
+func (b *schemaBuilder) buildSingleType(m map[string]any, t string) *schemaNode {
+	switch t {
+	case "object":
+		if m["properties"] != nil {
+			return b.buildObject(m, nil, nil)
+		}
+		if additional, ok := m["additionalProperties"].(map[string]any); ok {
+			elem := b.build(additional)
+			return &schemaNode{kind: schemaMap, typ: reflect.MapOf(reflect.TypeOf(""), elem.typ), elem: elem}
+		}
+
+	case "array":
+		if items, ok := m["items"].(map[string]any); ok {
+			elem := b.build(items)
+			return &schemaNode{kind: schemaArray, typ: reflect.SliceOf(elem.typ), elem: elem}
+		}
+
+	case "string":
+		if m["format"] == "password" || m["writeOnly"] == true {
+			return &schemaNode{kind: schemaString, typ: reflect.TypeOf(Password(""))}
+		}
+		return &schemaNode{kind: schemaString, typ: reflect.TypeOf("")}
+
+	case "integer":
+		if _, ok := m["x-units"].([]any); ok {
+			return &schemaNode{kind: schemaInteger, typ: reflect.TypeOf(Factor(0))}
+		}
+		return &schemaNode{kind: schemaInteger, typ: reflect.TypeOf(int64(0))}
+
+	case "number":
+		return &schemaNode{kind: schemaNumber, typ: reflect.TypeOf(float64(0))}
+
+	case "boolean":
+		return &schemaNode{kind: schemaBoolean, typ: reflect.TypeOf(false)}
+	}
+
+	return jsonTextNode
+}
+
+// buildAlternatives handles "oneOf" and "anyOf".
+func (b *schemaBuilder) buildAlternatives(m map[string]any, alternatives []any) *schemaNode {
+
+	// Nullable, e.g. anyOf: [{...}, {type: null}]
+	var nonNull []map[string]any
+	hasNull := false
+	for _, alt := range alternatives {
+		altMap, ok := alt.(map[string]any)
+		if !ok {
+			return jsonTextNode
+		}
+		if altMap["type"] == "null" {
+			hasNull = true
+		} else {
+			nonNull = append(nonNull, altMap)
+		}
+	}
+	if hasNull && len(nonNull) == 1 {
+		elem := b.build(nonNull[0])
+		if elem.kind == schemaJSON {
+			return elem
+		}
+		return nullableNode(elem)
+	}
+
+	// Titled constants, e.g. oneOf: [{const: 0, title: "Fast"}, ...]
+	allConst := !hasNull
+	for _, alt := range nonNull {
+		if _, ok := alt["const"]; !ok {
+			allConst = false
+		}
+	}
+	if allConst {
+		var values []any
+		var labels []string
+		for _, alt := range nonNull {
+			values = append(values, alt["const"])
+			labels = append(labels, enumLabel(alt["const"], alt["title"]))
+		}
+		return enumNode(values, labels)
+	}
+
+	if hasNull {
+		return jsonTextNode
+	}
+
+	// Objects. The properties of the parent schema are shared, except for any
+	// that are required by some other alternative.
+	baseProps, _ := m["properties"].(map[string]any)
+	requiredBy := make(map[string]int)
+	for _, alt := range nonNull {
+		for _, name := range stringList(alt["required"]) {
+			requiredBy[name]++
+		}
+	}
+
+	var fields []reflect.StructField
+	fields = append(fields, reflect.StructField{Name: "Option", Type: reflect.TypeOf(OneOf(""))})
+
+	ret := &schemaNode{kind: schemaOneOf}
+	used := map[string]bool{"Option": true}
+	for i, alt := range nonNull {
+		if t, _ := alt["type"].(string); t != "" && t != "object" {
+			return jsonTextNode
+		}
+
+		required := make(map[string]bool)
+		for _, name := range stringList(alt["required"]) {
+			required[name] = true
+		}
+
+		include := func(name string) bool {
+			return required[name] || requiredBy[name] == 0
+		}
+
+		option := b.buildObject(alt, baseProps, include)
+		ret.options = append(ret.options, option)
+
+		label, _ := alt["title"].(string)
+		if label == "" {
+			label = "Option " + strconv.Itoa(i+1)
+		}
+
+		fields = append(fields, reflect.StructField{
+			Name: goFieldName(label, used),
+			Type: reflect.PointerTo(option.typ),
+			Tag:  schemaTag(map[string]any{"title": label, "description": alt["description"]}, option),
+		})
+	}
+
+	ret.typ = reflect.StructOf(fields)
+	return ret
+}
+
+// buildObject builds a struct from the "properties" keyword. Properties from
+// the parent are also included, if the include function allows them.
+func (b *schemaBuilder) buildObject(m map[string]any, parentProps map[string]any, include func(name string) bool) *schemaNode {
+	props := make(map[string]any)
+	for name, prop := range parentProps {
+		if include(name) {
+			props[name] = prop
+		}
+	}
+	if own, ok := m["properties"].(map[string]any); ok {
+		for name, prop := range own {
+			props[name] = prop
+		}
+	}
+
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ret := &schemaNode{kind: schemaObject, consts: make(map[string]any)}
+	used := make(map[string]bool)
+	var fields []reflect.StructField
+
+	for _, name := range names {
+		propMap, _ := props[name].(map[string]any)
+		if c, ok := propMap["const"]; ok {
+			ret.consts[name] = c
+			continue
+		}
+
+		node := b.build(props[name])
+		ret.fields = append(ret.fields, schemaNodeField{name: name, node: node})
+
+		tagProps := map[string]any{"title": name}
+		for k, v := range propMap {
+			tagProps[k] = v
+		}
+
+		fields = append(fields, reflect.StructField{
+			Name: goFieldName(name, used),
+			Type: node.typ,
+			Tag:  schemaTag(tagProps, node),
+		})
+	}
+
+	ret.typ = reflect.StructOf(fields)
+	return ret
+}
+
+func stringList(v any) []string {
+	list, _ := v.([]any)
+	var ret []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
+
+func enumNode(values []any, labels []string) *schemaNode {
+	return &schemaNode{
+		kind:   schemaEnum,
+		typ:    reflect.TypeOf(EnumList(0)),
+		enum:   values,
+		labels: labels,
+	}
+}
+
+func nullableNode(elem *schemaNode) *schemaNode {
+	return &schemaNode{kind: schemaNullable, typ: reflect.PointerTo(elem.typ), elem: elem}
+}
+
+// enumLabel formats an enum value for display.
+func enumLabel(v any, title any) string {
+	if s, ok := title.(string); ok && s != "" {
+		return s
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// goFieldName makes a unique exported Go identifier from a property name.
+func goFieldName(name string, used map[string]bool) string {
+	var sb strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune('_')
+		}
+	}
+
+	ret := sb.String()
+	if ret == "" || !unicode.IsLetter([]rune(ret)[0]) {
+		ret = "F_" + ret
+	}
+	runes := []rune(ret)
+	runes[0] = unicode.ToUpper(runes[0])
+	if !unicode.IsUpper(runes[0]) {
+		ret = "F_" + ret // Letters without case
+	} else {
+		ret = string(runes)
+	}
+
+	base := ret
+	for i := 2; used[ret]; i++ {
+		ret = base + strconv.Itoa(i)
+	}
+	used[ret] = true
+	return ret
+}
+
+// schemaTag builds the struct tag for a property.
+func schemaTag(prop map[string]any, node *schemaNode) reflect.StructTag {
+	var parts []string
+	add := func(key, value string) {
+		parts = append(parts, key+":"+strconv.Quote(value))
+	}
+
+	if title, ok := prop["title"].(string); ok {
+		add("ylabel", title)
+	}
+	if desc, ok := prop["description"].(string); ok {
+		add("yhelp", desc)
+	}
+
+	inner := node
+	for inner.kind == schemaNullable {
+		inner = inner.elem
+	}
+
+	switch inner.kind {
+	case schemaEnum:
+		add("yenum", strings.Join(inner.labels, `;;`))
+
+	case schemaInteger:
+		if min, ok := prop["minimum"].(float64); ok {
+			add("ymin", strconv.FormatInt(int64(min), 10))
+		}
+		if max, ok := prop["maximum"].(float64); ok {
+			add("ymax", strconv.FormatInt(int64(max), 10))
+		}
+		if units, ok := prop["x-units"].([]any); ok {
+			var pairs []string
+			for _, unit := range units {
+				u, _ := unit.(map[string]any)
+				mult, _ := u["multiplier"].(float64)
+				name, _ := u["name"].(string)
+				pairs = append(pairs, strconv.FormatInt(int64(mult), 10), name)
+			}
+			add("yfactor", strings.Join(pairs, `;;`))
+		}
+
+	case schemaNumber:
+		if min, ok := prop["minimum"].(float64); ok {
+			add("ymin", strconv.FormatFloat(min, 'g', -1, 64))
+		}
+		if max, ok := prop["maximum"].(float64); ok {
+			add("ymax", strconv.FormatFloat(max, 'g', -1, 64))
+		}
+	}
+
+	return reflect.StructTag(strings.Join(parts, " "))
+}
+
+// fromJSON sets the value from a JSON value, as decoded by encoding/json into
+// an interface.
+func (n *schemaNode) fromJSON(rv reflect.Value, v any) {
+	switch n.kind {
+	case schemaObject:
+		m, _ := v.(map[string]any)
+		for i, f := range n.fields {
+			val, ok := m[f.name]
+			if !ok {
+				val, ok = f.node.def, f.node.def != nil
+			}
+			if ok {
+				f.node.fromJSON(rv.Field(i), val)
+			}
+		}
+
+	case schemaOneOf:
+		m, _ := v.(map[string]any)
+		idx := n.selectOption(m)
+		if idx == -1 {
+			return
+		}
+		schema.SelectOneOf(rv, idx+1)
+		ptr := rv.Field(idx + 1)
+		ptr.Set(reflect.New(ptr.Type().Elem()))
+		n.options[idx].fromJSON(ptr.Elem(), m)
+
+	case schemaMap:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return
+		}
+		rv.Set(reflect.MakeMapWithSize(rv.Type(), len(m)))
+		for k, val := range m {
+			elem := reflect.New(n.elem.typ).Elem()
+			n.elem.fromJSON(elem, val)
+			rv.SetMapIndex(reflect.ValueOf(k), elem)
+		}
+
+	case schemaArray:
+		list, ok := v.([]any)
+		if !ok {
+			return
+		}
+		rv.Set(reflect.MakeSlice(rv.Type(), len(list), len(list)))
+		for i, val := range list {
+			n.elem.fromJSON(rv.Index(i), val)
+		}
+
+	case schemaNullable:
+		if v == nil {
+			return
+		}
+		rv.Set(reflect.New(n.elem.typ))
+		n.elem.fromJSON(rv.Elem(), v)
+
+	case schemaEnum:
+		for i, opt := range n.enum {
+			if reflect.DeepEqual(opt, v) {
+				rv.SetInt(int64(i))
+			}
+		}
+
+	case schemaString:
+		if s, ok := v.(string); ok {
+			rv.SetString(s)
+		}
+
+	case schemaInteger:
+		if f, ok := v.(float64); ok {
+			rv.SetInt(int64(f))
+		}
+
+	case schemaNumber:
+		if f, ok := v.(float64); ok {
+			rv.SetFloat(f)
+		}
+
+	case schemaBoolean:
+		if b, ok := v.(bool); ok {
+			rv.SetBool(b)
+		}
+
+	case schemaJSON:
+		data, err := json.MarshalIndent(v, "", "\t")
+		if err == nil {
+			rv.SetString(string(data))
+		}
+	}
+}
+
+// selectOption finds the alternative that matches the object. Alternatives with
+// matching constants are preferred, then alternatives where all the properties
+// are present.
+func (n *schemaNode) selectOption(m map[string]any) int {
+	if m == nil {
+		return -1
+	}
+
+	for i, option := range n.options {
+		if len(option.consts) == 0 {
+			continue
+		}
+		match := true
+		for k, c := range option.consts {
+			if !reflect.DeepEqual(m[k], c) {
+				match = false
+			}
+		}
+		if match {
+			return i
+		}
+	}
+
+	for i, option := range n.options {
+		match := true
+		for _, f := range option.fields {
+			if _, ok := m[f.name]; !ok {
+				match = false
+			}
+		}
+		if match {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// toJSON gets the JSON value for the Go value. The previous JSON value is used
+// to keep any properties that are not in the schema. It returns an error if a
+// value that is edited as JSON text is not valid JSON.
+func (n *schemaNode) toJSON(rv reflect.Value, prev any) (any, error) {
+	switch n.kind {
+	case schemaObject:
+		prevMap, _ := prev.(map[string]any)
+		ret := make(map[string]any, len(prevMap))
+		for k, v := range prevMap {
+			ret[k] = v
+		}
+		for i, f := range n.fields {
+			prevVal, had := prevMap[f.name]
+			if had || !rv.Field(i).IsZero() {
+				val, err := f.node.toJSON(rv.Field(i), prevVal)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", f.name, err)
+				}
+				ret[f.name] = val
+			}
+		}
+		for k, c := range n.consts {
+			ret[k] = c
+		}
+		return ret, nil
+
+	case schemaOneOf:
+		prevMap, _ := prev.(map[string]any)
+		selected := rv.Field(0).String()
+		selectedIdx := -1
+		for i := range n.options {
+			if rv.Type().Field(i+1).Name == selected && !rv.Field(i+1).IsNil() {
+				selectedIdx = i
+			}
+		}
+
+		// Drop the properties of the other alternatives, unless they are
+		// shared with the selected one
+		keep := make(map[string]bool)
+		if selectedIdx != -1 {
+			for _, name := range n.options[selectedIdx].propertyNames() {
+				keep[name] = true
+			}
+		}
+		drop := make(map[string]bool)
+		for i, option := range n.options {
+			if i == selectedIdx {
+				continue
+			}
+			for _, name := range option.propertyNames() {
+				if !keep[name] {
+					drop[name] = true
+				}
+			}
+		}
+
+		ret := make(map[string]any, len(prevMap))
+		for k, v := range prevMap {
+			if !drop[k] {
+				ret[k] = v
+			}
+		}
+
+		if selectedIdx == -1 {
+			return ret, nil
+		}
+		return n.options[selectedIdx].toJSON(rv.Field(selectedIdx+1).Elem(), ret)
+
+	case schemaMap:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		prevMap, _ := prev.(map[string]any)
+		ret := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			k := iter.Key().String()
+			val, err := n.elem.toJSON(iter.Value(), prevMap[k])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			ret[k] = val
+		}
+		return ret, nil
+
+	case schemaArray:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		prevList, _ := prev.([]any)
+		ret := make([]any, rv.Len())
+		for i := range ret {
+			var prevVal any
+			if i < len(prevList) {
+				prevVal = prevList[i]
+			}
+			val, err := n.elem.toJSON(rv.Index(i), prevVal)
+			if err != nil {
+				return nil, fmt.Errorf("%d: %w", i, err)
+			}
+			ret[i] = val
+		}
+		return ret, nil
+
+	case schemaNullable:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return n.elem.toJSON(rv.Elem(), prev)
+
+	case schemaEnum:
+		if idx := int(rv.Int()); idx >= 0 && idx < len(n.enum) {
+			return n.enum[idx], nil
+		}
+		return prev, nil
+
+	case schemaString:
+		return rv.String(), nil
+
+	case schemaInteger:
+		return rv.Int(), nil
+
+	case schemaNumber:
+		return rv.Float(), nil
+
+	case schemaBoolean:
+		return rv.Bool(), nil
+
+	case schemaJSON:
+		var ret any
+		if err := json.Unmarshal([]byte(rv.String()), &ret); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return ret, nil
+	}
+
+	panic(fmt.Sprintf("schemaNode: unexpected kind %d", n.kind))
+}
+
+// propertyNames gets the JSON properties of an object, including constants.
+func (n *schemaNode) propertyNames() []string {
+	ret := make([]string, 0, len(n.fields)+len(n.consts))
+	for _, f := range n.fields {
+		ret = append(ret, f.name)
+	}
+	for k := range n.consts {
+		ret = append(ret, k)
+	}
+	return ret
+}
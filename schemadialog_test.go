@@ -0,0 +1,142 @@
+package autoconfig
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mappu/autoconfig/schema"
+)
+
+func TestSchemaValue(t *testing.T) {
+	js, err := json.Marshal(JSONSchema(reflect.TypeOf(testDescribeStruct{})))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var jsonSchema map[string]any
+	if err := json.Unmarshal(js, &jsonSchema); err != nil {
+		t.Fatal(err)
+	}
+
+	input := `{
+		"Cache": 1048576,
+		"Mode": 1,
+		"Address": {"Address": "localhost", "Port": 80},
+		"Secret": "hunter2",
+		"Plain": 5,
+		"Transport": {"Mode": "Unix", "Unix": "/run/app.sock"},
+		"Tabs": {"General": {"Name": "foo"}},
+		"Unknown": [1, 2]
+	}`
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(input), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	node, rv, err := newSchemaValue(jsonSchema, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ct := rv.Elem()
+	if got := ct.FieldByName("Mode").Interface(); got != EnumList(1) {
+		t.Errorf("Mode: got %v, want 1", got)
+	}
+	if got := ct.FieldByName("Cache").Interface(); got != Factor(1048576) {
+		t.Errorf("Cache: got %v, want 1048576", got)
+	}
+	if got := ct.FieldByName("Secret").Elem().Interface(); got != Password("hunter2") {
+		t.Errorf("Secret: got %v, want hunter2", got)
+	}
+	if ff, _ := ct.Type().FieldByName("Cache"); ff.Tag.Get("yfactor") == "" {
+		t.Errorf("Cache: missing yfactor tag in %q", ff.Tag)
+	}
+
+	transport := ct.FieldByName("Transport")
+	if got := transport.Field(0).String(); got != transport.Type().Field(3).Name {
+		t.Errorf("Transport: got option %q, want the third option, after None", got)
+	}
+
+	ct.FieldByName("Plain").SetInt(6)
+
+	val, err := node.toJSON(ct, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc["Plain"] = 6
+	want, _ := json.Marshal(doc)
+	if string(got) != string(want) {
+		t.Errorf("toJSON:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestSchemaValueSwitchOption(t *testing.T) {
+	js, err := json.Marshal(JSONSchema(reflect.TypeOf(testDescribeStruct{})))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var jsonSchema map[string]any
+	if err := json.Unmarshal(js, &jsonSchema); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := map[string]any{
+		"Transport": map[string]any{"Mode": "Unix", "Unix": "/run/app.sock", "Extra": true},
+	}
+
+	node, rv, err := newSchemaValue(jsonSchema, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Switch to the TCP option, after None
+	transport := rv.Elem().FieldByName("Transport")
+	schema.SelectOneOf(transport, 2)
+	transport.Field(2).Set(reflect.New(transport.Field(2).Type().Elem()))
+
+	val, err := node.toJSON(rv.Elem(), doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := val.(map[string]any)["Transport"].(map[string]any)
+	if _, ok := got["Unix"]; ok {
+		t.Errorf("Transport: the Unix property was kept, got %v", got)
+	}
+	if got["Mode"] != "TCP" || got["Extra"] != true {
+		t.Errorf("Transport: got %v", got)
+	}
+}
+
+func TestSchemaValueInvalidJSON(t *testing.T) {
+	jsonSchema := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"Extra": map[string]any{}},
+	}
+	doc := map[string]any{"Extra": []any{1.0, 2.0}}
+
+	node, rv, err := newSchemaValue(jsonSchema, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rv.Elem().Field(0).SetString("[1, 2")
+	if _, err := node.toJSON(rv.Elem(), doc); err == nil || !strings.Contains(err.Error(), "Extra") {
+		t.Errorf("expected an error for the Extra property, got %v", err)
+	}
+}
+
+func TestSchemaValueNotObject(t *testing.T) {
+	_, _, err := newSchemaValue(map[string]any{"type": "string"}, nil)
+	if err == nil {
+		t.Errorf("expected error for a non-object schema")
+	}
+}
@@ -1,12 +1,19 @@
 package autoconfig
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/mappu/autoconfig/schema"
 	qt "github.com/mappu/miqt/qt6"
 )
 
 // setIcon preferentially sets an icon for a button, using a label if the icon
 // is not found.
 func setIcon(btn *qt.QAbstractButton, iconThemeName, fallbackLabel, tooltip string) {
+	fallbackLabel = tr(fallbackLabel)
+	tooltip = tr(tooltip)
+
 	if qt.QIcon_HasThemeIcon(iconThemeName) {
 		btn.SetIcon(qt.QIcon_FromTheme(iconThemeName))
 	} else {
@@ -16,26 +23,88 @@ func setIcon(btn *qt.QAbstractButton, iconThemeName, fallbackLabel, tooltip stri
 	if fallbackLabel != tooltip {
 		btn.SetToolTip(tooltip)
 	}
+
+	// Screen readers can't read an icon
+	btn.SetAccessibleName(tooltip)
 }
 
 // addRow adds the widget and label to the layout. It handles the case of a
 // blank label.
+// The label may contain an "&" mnemonic, which focuses the widget (or its
+// focus proxy). The widget is also named for screen readers, if it was not
+// named already.
 func addRow(area *qt.QFormLayout, label string, widget *qt.QWidget) {
 	if label == "" {
 		area.AddRowWithWidget(widget) // No label
-	} else {
-		area.AddRow3(label+`:`, widget)
+		return
+	}
+
+	name := schema.StripMnemonic(label)
+	for _, w := range []*qt.QWidget{widget, widget.FocusProxy()} {
+		if w != nil && w.AccessibleName() == "" {
+			w.SetAccessibleName(name)
+		}
 	}
+
+	area.AddRow3(label+`:`, widget) // Sets the widget as the label's buddy
 }
 
-// addRowLayout adds the widget and label to the layout. It handles the case of a
+// addRowLayout adds the layout and label to the form. It handles the case of a
 // blank label.
-func addRowLayout(area *qt.QFormLayout, label string, layout *qt.QLayout) {
-	if label == "" {
-		area.AddRowWithLayout(layout) // No label
-	} else {
-		area.AddRow4(label+`:`, layout)
+// The layout is placed in a container widget, so that the label's mnemonic
+// can focus the given widget.
+func addRowLayout(area *qt.QFormLayout, label string, layout *qt.QLayout, focus *qt.QWidget) {
+	container := qt.NewQWidget(area.ParentWidget())
+	layout.SetContentsMargins(0, 0, 0, 0)
+	container.SetLayout(layout)
+	container.SetFocusProxy(focus)
+
+	addRow(area, label, container)
+}
+
+// accessiblePart is one of the inputs in a composite widget, see
+// setAccessibleParts.
+type accessiblePart struct {
+	widget *qt.QWidget
+	name   string // Translated format for the name, with a "%s" placeholder for the label
+}
+
+// setAccessibleParts names each input of a composite widget for screen
+// readers, e.g. "Listen address" and "Listen port", and forwards the focus to
+// the first input, so that the label's mnemonic focuses it.
+func setAccessibleParts(container *qt.QWidget, label string, parts ...accessiblePart) {
+	name := schema.StripMnemonic(label)
+	for _, part := range parts {
+		part.widget.SetAccessibleName(strings.TrimSpace(fmt.Sprintf(part.name, name)))
+	}
+
+	container.SetFocusProxy(parts[0].widget)
+}
+
+// setAccessibleDescription describes the inputs in the rows for screen
+// readers, e.g. from the `yhelp` tag.
+func setAccessibleDescription(area *qt.QFormLayout, firstRow, endRow int, text string) {
+	for _, w := range rowWidgets(area, firstRow, endRow) {
+		if proxy := w.FocusProxy(); proxy != nil {
+			w = proxy
+		}
+		if w.AccessibleDescription() == "" {
+			w.SetAccessibleDescription(text)
+		}
+	}
+}
+
+// rowWidgets gets the field widgets in the rows, not including the labels.
+func rowWidgets(area *qt.QFormLayout, firstRow, endRow int) []*qt.QWidget {
+	var ret []*qt.QWidget
+	for row := firstRow; row < endRow; row++ {
+		for _, role := range []qt.QFormLayout__ItemRole{qt.QFormLayout__FieldRole, qt.QFormLayout__SpanningRole} {
+			if item := area.ItemAt(row, role); item != nil && item.Widget() != nil {
+				ret = append(ret, item.Widget())
+			}
+		}
 	}
+	return ret
 }
 
 // addRowBoxAndButtons adds the mainwidget and its buttons to the layout.
@@ -63,5 +132,5 @@ func addRowBoxAndButtons(area *qt.QFormLayout, label string, mainWidget *qt.QWid
 
 	hbox.AddLayout(vbox.QLayout)
 
-	addRowLayout(area, label, hbox.QLayout)
+	addRowLayout(area, label, hbox.QLayout, mainWidget)
 }
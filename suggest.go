@@ -0,0 +1,111 @@
+package autoconfig
+
+import (
+	"reflect"
+
+	"github.com/mappu/autoconfig/schema"
+	qt "github.com/mappu/miqt/qt6"
+)
+
+// Suggester is implemented by a field's type, or by the struct that has the
+// field, to suggest values for a free-text field. See schema.Suggester.
+type Suggester = schema.Suggester
+
+const maxRecentValues = 10
+
+// WithRecentValues remembers the values that the user enters in text fields,
+// and suggests them again the next time the dialog is opened. The values are
+// stored in the QSettings object under the supplied group name, with the field
+// path as the key, so use a different group for each type of struct.
+// If settings is nil, the values are only remembered until the program exits.
+func WithRecentValues(settings *qt.QSettings, group string) Option {
+	return func(o *options) {
+		o.recent = &recentValues{settings: settings, group: group}
+	}
+}
+
+// recentValues is the history of the values entered in text fields, for
+// WithRecentValues.
+type recentValues struct {
+	settings *qt.QSettings
+	group    string
+}
+
+// memoryRecentValues holds the history for WithRecentValues without a
+// QSettings object, by group and then field path.
+var memoryRecentValues = map[string]map[string][]string{}
+
+// get gets the recent values for the field at the path, newest first.
+func (r *recentValues) get(path string) []string {
+	if r.settings == nil {
+		return memoryRecentValues[r.group][path]
+	}
+
+	r.settings.BeginGroup(qsKey(r.group))
+	defer r.settings.EndGroup()
+	return r.settings.ValueWithKey(qsKey(path)).ToStringList()
+}
+
+// add moves the value to the front of the recent values for the field at the
+// path, and forgets the oldest values beyond maxRecentValues.
+func (r *recentValues) add(path string, value string) {
+	values := []string{value}
+	for _, v := range r.get(path) {
+		if v != value && len(values) < maxRecentValues {
+			values = append(values, v)
+		}
+	}
+
+	if r.settings == nil {
+		if memoryRecentValues[r.group] == nil {
+			memoryRecentValues[r.group] = map[string][]string{}
+		}
+		memoryRecentValues[r.group][path] = values
+		return
+	}
+
+	r.settings.BeginGroup(qsKey(r.group))
+	defer r.settings.EndGroup()
+	r.settings.SetValue(qsKey(path), qt.NewQVariant15(values))
+}
+
+// addCompleter adds autocomplete suggestions to a text box, for the value at
+// the activeForm path. The suggestions are the recent values, then those from
+// schema.Suggestions, and then any extra options.
+func (f *formContext) addCompleter(line *qt.QLineEdit, rv *reflect.Value, tag reflect.StructTag, extra ...string) {
+	if f.readOnly {
+		return
+	}
+
+	path := f.path
+	var recent []string
+	if f.recent != nil && path != "" {
+		recent = f.recent.get(path)
+
+		initial := line.Text()
+		line.OnEditingFinished(func() {
+			if text := line.Text(); text != "" && text != initial {
+				f.recent.add(path, text)
+			}
+		})
+	}
+
+	var completions []string
+	seen := map[string]bool{}
+	for _, list := range [][]string{recent, schema.Suggestions(f.owner, *rv, tag, path), extra} {
+		for _, s := range list {
+			if !seen[s] {
+				seen[s] = true
+				completions = append(completions, s)
+			}
+		}
+	}
+	if len(completions) == 0 {
+		return
+	}
+
+	completer := qt.NewQCompleter6(completions, line.QObject)
+	completer.SetCaseSensitivity(qt.CaseInsensitive)
+	completer.SetFilterMode(qt.MatchContains)
+	line.SetCompleter(completer)
+}
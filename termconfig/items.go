@@ -0,0 +1,292 @@
+package termconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/mappu/autoconfig/schema"
+)
+
+// itemsFor gets the items to show for a struct, TabGroup, GroupBox or OneOf.
+func itemsFor(f *schema.Field, rv reflect.Value) []item {
+	f = f.Expand()
+
+	if f.Kind == schema.KindOneOf {
+		return oneOfItems(f, rv)
+	}
+
+	var ret []item
+	group := ""
+	for _, child := range f.FormFields() {
+		// Start each `ygroup` with a header
+		if child.Group != group && child.Group != "" {
+			ret = append(ret, item{label: child.Group, header: true})
+		}
+		group = child.Group
+
+		switch {
+		case child.Kind == schema.KindHeader:
+			ret = append(ret, item{label: child.Label, header: true})
+		case child.Embedded:
+			ret = append(ret, itemsFor(child, rv.FieldByIndex(child.IndexPath))...)
+		default:
+			owner := rv.FieldByIndex(child.IndexPath[:len(child.IndexPath)-1])
+			ret = append(ret, item{label: child.Label, field: child, rv: rv.FieldByIndex(child.IndexPath), owner: owner})
+		}
+	}
+	return ret
+}
+
+// oneOfItems shows the selector, followed by the selected option.
+func oneOfItems(f *schema.Field, rv reflect.Value) []item {
+	selector := item{
+		label:    "Type",
+		field:    f,
+		rv:       rv,
+		selector: true,
+	}
+
+	options := f.FormFields()
+	current := -1
+	var labels []string
+	for i, option := range options {
+		labels = append(labels, option.Label)
+		if option.Name == rv.Field(0).String() {
+			current = i
+		}
+	}
+
+	ret := []item{selector}
+	if current == -1 {
+		return ret
+	}
+
+	option := options[current]
+	ptr := rv.Field(option.Index)
+	if option.Kind == schema.KindPointer {
+		if ptr.IsNil() {
+			return ret
+		}
+		option = option.Elem
+		ptr = ptr.Elem()
+	}
+
+	if option.Kind == schema.KindStruct {
+		return append(ret, itemsFor(option, ptr)...)
+	}
+	return append(ret, item{label: options[current].Label, field: option, rv: ptr, owner: rv})
+}
+
+// summary formats the value of an item.
+func summary(f *schema.Field, rv reflect.Value) string {
+	switch f.Kind {
+	case schema.KindPointer:
+		if rv.IsNil() {
+			return "Not configured"
+		}
+		return summary(f.Elem, rv.Elem())
+
+	case schema.KindBool:
+		if rv.Bool() {
+			return "[x]"
+		}
+		return "[ ]"
+
+	case schema.KindStruct, schema.KindTabGroup, schema.KindGroupBox:
+		return "›"
+
+	case schema.KindOneOf:
+		current := rv.Field(0).String()
+		for i := 1; i < rv.NumField(); i++ {
+			if ff := rv.Type().Field(i); ff.Name == current {
+				return schema.FieldLabel(rv.Type(), ff) + " ›"
+			}
+		}
+		return "Not selected ›"
+
+	case schema.KindSlice, schema.KindArray:
+		return plural(rv.Len(), "item") + " ›"
+
+	case schema.KindMap:
+		return plural(rv.Len(), "entry") + " ›"
+
+	case schema.KindHeader:
+		return ""
+	}
+
+	if f.Secret {
+		if rv.String() == "" {
+			return ""
+		}
+		return "••••••"
+	}
+
+	if schema.IsText(rv.Type()) {
+		return schema.FormatText(rv, f.Tag)
+	}
+
+	return display(rv)
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	if noun == "entry" {
+		return strconv.Itoa(n) + " entries"
+	}
+	return strconv.Itoa(n) + " " + noun + "s"
+}
+
+// display formats a value that can't be edited.
+func display(rv reflect.Value) string {
+	switch rv.Kind() {
+	case reflect.Func, reflect.Chan, reflect.Interface, reflect.UnsafePointer:
+		if rv.IsNil() {
+			return "Not configured"
+		}
+		return "Configured"
+	}
+	if !rv.CanInterface() {
+		return ""
+	}
+	return fmt.Sprint(rv.Interface())
+}
+
+// newElem creates a new addressable value for a slice element or map entry,
+// reset to defaults if it implements autoconfig.Resetter.
+func newElem(t reflect.Type) reflect.Value {
+	ret := reflect.New(t)
+	if defaulter, ok := ret.Interface().(interface{ Reset() }); ok {
+		defaulter.Reset()
+	}
+	return ret.Elem()
+}
+
+// listPage shows the elements of a slice or array.
+func (e *editor) listPage(it *item) *page {
+	f := it.field
+	rv := it.rv
+
+	p := &page{
+		title:   it.label,
+		onClose: it.commit,
+	}
+
+	p.items = func() []item {
+		var ret []item
+		for i := 0; i < rv.Len(); i++ {
+			idx := i
+			elem := item{label: strconv.Itoa(i + 1), field: f.Elem, rv: rv.Index(i), owner: it.owner}
+			if rv.Kind() == reflect.Slice {
+				elem.remove = func() {
+					rv.Set(reflect.AppendSlice(rv.Slice(0, idx), rv.Slice(idx+1, rv.Len())))
+				}
+			}
+			ret = append(ret, elem)
+		}
+		return ret
+	}
+
+	if rv.Kind() == reflect.Slice {
+		p.add = func() {
+			rv.Set(reflect.Append(rv, newElem(rv.Type().Elem())))
+		}
+	}
+
+	return p
+}
+
+// mapPage shows the entries of a map. Map values are not addressable, so each
+// entry is edited as a copy that is stored back into the map afterwards.
+func (e *editor) mapPage(it *item) *page {
+	f := it.field
+	rv := it.rv
+	keyField := &schema.Field{Kind: schema.Classify(rv.Type().Key(), f.Tag), Type: rv.Type().Key(), Tag: f.Tag}
+
+	p := &page{
+		title:   it.label,
+		onClose: it.commit,
+	}
+
+	p.items = func() []item {
+		keys := rv.MapKeys()
+		sort.SliceStable(keys, func(i, j int) bool {
+			return summary(keyField, keys[i]) < summary(keyField, keys[j])
+		})
+
+		var ret []item
+		for _, k := range keys {
+			k := k
+			val := newElem(rv.Type().Elem())
+			val.Set(rv.MapIndex(k))
+
+			ret = append(ret, item{
+				label:  summary(keyField, k),
+				field:  f.Elem,
+				rv:     val,
+				owner:  it.owner,
+				commit: func() { rv.SetMapIndex(k, val) },
+				remove: func() { rv.SetMapIndex(k, reflect.Value{}) },
+				rename: func() { e.renameKey(rv, keyField, k) },
+			})
+		}
+		return ret
+	}
+
+	p.add = func() {
+		key := newElem(rv.Type().Key())
+		if schema.IsText(key.Type()) {
+			text, ok := e.readLine("New key", "", "", false)
+			if !ok {
+				return
+			}
+			if err := schema.ParseText(key, f.Tag, text); err != nil {
+				e.message = "Error: " + err.Error()
+				return
+			}
+		}
+
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMap(rv.Type()))
+		}
+		if rv.MapIndex(key).IsValid() {
+			e.message = "Error: the key already exists"
+			return
+		}
+		rv.SetMapIndex(key, newElem(rv.Type().Elem()))
+	}
+
+	return p
+}
+
+func (e *editor) renameKey(rv reflect.Value, keyField *schema.Field, k reflect.Value) {
+	if !schema.IsText(k.Type()) {
+		e.message = "This key can't be changed here."
+		return
+	}
+
+	text, ok := e.readLine("Rename key", schema.FormatText(k, keyField.Tag), "", false)
+	if !ok {
+		return
+	}
+
+	newKey := newElem(k.Type())
+	if err := schema.ParseText(newKey, keyField.Tag, text); err != nil {
+		e.message = "Error: " + err.Error()
+		return
+	}
+
+	if newKey.Interface() == k.Interface() {
+		return
+	}
+	if rv.MapIndex(newKey).IsValid() {
+		e.message = "Error: the key already exists"
+		return
+	}
+
+	rv.SetMapIndex(newKey, rv.MapIndex(k))
+	rv.SetMapIndex(k, reflect.Value{})
+}
@@ -0,0 +1,105 @@
+package termconfig
+
+import (
+	"bufio"
+	"unicode"
+)
+
+// key is a single keypress.
+type key struct {
+	code keyCode
+	r    rune // For keyRune
+}
+
+type keyCode int
+
+const (
+	keyNone keyCode = iota // Unknown key or escape sequence
+	keyRune
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keyEscape
+	keyBackspace
+	keyInterrupt // Ctrl+C
+	keyClearLine // Ctrl+U
+	keyEOF
+)
+
+// readKey reads a single keypress, decoding ANSI escape sequences for the
+// arrow keys.
+func readKey(in *bufio.Reader) key {
+	r, _, err := in.ReadRune()
+	if err != nil {
+		return key{code: keyEOF}
+	}
+
+	switch r {
+	case '\r', '\n':
+		// Raw terminals send \r, scripted input may use \r\n
+		if r == '\r' && in.Buffered() > 0 {
+			if next, _ := in.Peek(1); next[0] == '\n' {
+				in.ReadByte()
+			}
+		}
+		return key{code: keyEnter}
+
+	case 0x7f, 0x08:
+		return key{code: keyBackspace}
+
+	case 0x03:
+		return key{code: keyInterrupt}
+
+	case 0x15:
+		return key{code: keyClearLine}
+
+	case 0x1b:
+		// A bare Escape, unless it is immediately followed by a sequence
+		if in.Buffered() == 0 {
+			return key{code: keyEscape}
+		}
+		next, _ := in.Peek(1)
+		if next[0] != '[' && next[0] != 'O' {
+			return key{code: keyEscape}
+		}
+		in.ReadByte()
+		return readEscapeSequence(in)
+	}
+
+	if !unicode.IsPrint(r) {
+		return key{code: keyNone} // Other control characters
+	}
+
+	return key{code: keyRune, r: r}
+}
+
+// readEscapeSequence reads the rest of a CSI or SS3 sequence, after the
+// "ESC [" or "ESC O" prefix.
+func readEscapeSequence(in *bufio.Reader) key {
+	for {
+		b, err := in.ReadByte()
+		if err != nil {
+			return key{code: keyEOF}
+		}
+
+		// Parameter bytes are digits and ';', the final byte is a letter or '~'
+		if (b >= '0' && b <= '9') || b == ';' {
+			continue
+		}
+
+		switch b {
+		case 'A':
+			return key{code: keyUp}
+		case 'B':
+			return key{code: keyDown}
+		case 'C':
+			return key{code: keyRight}
+		case 'D':
+			return key{code: keyLeft}
+		default:
+			return key{code: keyNone}
+		}
+	}
+}
@@ -0,0 +1,507 @@
+// Package termconfig edits a configurable struct in a text terminal, for use
+// when no display is available (e.g. over SSH).
+//
+// It supports the same struct types and tags as autoconfig.MakeConfigArea, and
+// does not depend on Qt. The terminal is driven through an io.Reader and an
+// io.Writer using ANSI escape sequences, so it can also be tested with scripted
+// input.
+package termconfig
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/mappu/autoconfig/schema"
+)
+
+// Edit shows an interactive editor for the configurable struct, which must be a
+// non-nil pointer.
+//
+// Keys:
+//   - Up/Down (or k/j) to move between fields
+//   - Enter (or Right) to edit a field, or to open a child struct or list
+//   - Escape (or Left, q) to go back, or to exit without saving
+//   - a to add, d to delete, and n to rename a list or map item
+//   - c to clear, and r to reset an optional (pointer) field
+//   - s to save and exit, or Ctrl+C to exit without saving
+//
+// Changes are only applied to the struct when saving. The return value
+// reports if the changes were saved.
+//
+// For single keypresses to be received, the terminal should be in raw mode
+// (e.g. with golang.org/x/term.MakeRaw). Otherwise, each line of input is only
+// received after pressing Enter.
+//
+// The options change which fields are shown, e.g. schema.HideJSONIgnored.
+func Edit(ct any, title string, in io.Reader, out io.Writer, opts ...schema.LayoutOption) (bool, error) {
+	rv := reflect.ValueOf(ct)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		panic("termconfig: expected a non-nil pointer to a struct, got " + rv.Type().String()) // Programmer error
+	}
+
+	work := schema.Clone(rv.Elem())
+
+	e := editor{
+		in:    bufio.NewReader(in),
+		out:   out,
+		title: title,
+	}
+
+	root := schema.DescribeType(rv.Type(), opts...).Root
+	e.push(&page{
+		title: title,
+		items: func() []item { return itemsFor(root, work) },
+	})
+
+	saved, err := e.run()
+	if err != nil || !saved {
+		return false, err
+	}
+
+	rv.Elem().Set(work)
+	return true, nil
+}
+
+// item is a single line on a page.
+type item struct {
+	label  string
+	field  *schema.Field // nil for headers and options
+	rv     reflect.Value // Settable
+	owner  reflect.Value // The struct that has the field, for EnumStringOptionsFor
+	header bool
+
+	commit func() // Called after editing, e.g. to store a map value
+	remove func() // Removes a list or map item
+	rename func() // Renames a map item
+
+	selector bool // Selects the option of a OneOf
+}
+
+// page is a list of items.
+type page struct {
+	title   string
+	items   func() []item
+	add     func()    // Adds a list or map item, or nil
+	choose  func(int) // For a list of options, called when one is chosen
+	onClose func()
+	cursor  int
+}
+
+type editor struct {
+	in      *bufio.Reader
+	out     io.Writer
+	title   string
+	stack   []*page
+	message string
+	err     error
+}
+
+func (e *editor) push(p *page) {
+	e.stack = append(e.stack, p)
+}
+
+func (e *editor) pop() {
+	top := e.stack[len(e.stack)-1]
+	e.stack = e.stack[:len(e.stack)-1]
+	if top.onClose != nil {
+		top.onClose()
+	}
+}
+
+func (e *editor) run() (bool, error) {
+	for {
+		p := e.stack[len(e.stack)-1]
+		items := p.items()
+		p.cursor = moveCursor(items, p.cursor, 0)
+
+		e.draw(p, items)
+		if e.err != nil {
+			return false, e.err
+		}
+		e.message = ""
+
+		k := readKey(e.in)
+
+		var current *item
+		if p.cursor < len(items) && !items[p.cursor].header {
+			current = &items[p.cursor]
+		}
+
+		switch {
+		case k.code == keyEOF, k.code == keyInterrupt:
+			return false, nil
+
+		case k.code == keyUp, k.code == keyRune && k.r == 'k':
+			p.cursor = moveCursor(items, p.cursor, -1)
+
+		case k.code == keyDown, k.code == keyRune && k.r == 'j':
+			p.cursor = moveCursor(items, p.cursor, 1)
+
+		case k.code == keyEnter, k.code == keyRight, k.code == keyRune && k.r == 'l':
+			if current == nil {
+				break
+			}
+			if p.choose != nil {
+				e.pop()
+				p.choose(p.cursor)
+				break
+			}
+			e.activate(current)
+
+		case k.code == keyEscape, k.code == keyLeft, k.code == keyRune && (k.r == 'q' || k.r == 'h'):
+			if len(e.stack) == 1 {
+				if k.code == keyLeft || k.r == 'h' {
+					break // Already at the top
+				}
+				return false, nil
+			}
+			e.pop()
+
+		case k.code == keyRune && k.r == 's':
+			for len(e.stack) > 1 {
+				e.pop()
+			}
+			return true, nil
+
+		case k.code == keyRune && k.r == 'a':
+			if p.add != nil {
+				p.add()
+				p.cursor = len(p.items()) - 1
+			}
+
+		case k.code == keyRune && k.r == 'd':
+			if current != nil && current.remove != nil {
+				current.remove()
+			}
+
+		case k.code == keyRune && k.r == 'n':
+			if current != nil && current.rename != nil {
+				current.rename()
+			}
+
+		case k.code == keyRune && k.r == 'c':
+			if current != nil && current.field != nil && current.field.Kind == schema.KindPointer {
+				current.rv.SetZero()
+				e.commit(current)
+			}
+
+		case k.code == keyRune && k.r == 'r':
+			if current != nil && current.field != nil && current.field.Kind == schema.KindPointer && current.rv.Type().Implements(resetterType) {
+				schema.Allocate(current.rv)
+				e.commit(current)
+			}
+		}
+	}
+}
+
+var resetterType = reflect.TypeOf((*interface{ Reset() })(nil)).Elem()
+
+// moveCursor moves the cursor by the offset, skipping over headers.
+func moveCursor(items []item, cursor int, offset int) int {
+	if len(items) == 0 {
+		return 0
+	}
+
+	step := offset
+	if step == 0 {
+		step = 1
+	}
+
+	next := cursor + offset
+	for next >= 0 && next < len(items) && items[next].header {
+		next += step
+	}
+
+	if next < 0 || next >= len(items) {
+		if offset == 0 && cursor >= len(items) {
+			return len(items) - 1
+		}
+		return cursor // Can't move
+	}
+	return next
+}
+
+func (e *editor) commit(it *item) {
+	if it.commit != nil {
+		it.commit()
+	}
+}
+
+// activate edits an item, or opens it as a new page.
+func (e *editor) activate(it *item) {
+	if it.selector {
+		e.chooseOneOf(it)
+		return
+	}
+
+	f := it.field
+	rv := it.rv
+
+	switch f.Kind {
+	case schema.KindPointer:
+		if rv.IsNil() {
+			schema.Allocate(rv)
+		}
+		e.activate(&item{label: it.label, field: f.Elem, rv: rv.Elem(), owner: it.owner, commit: it.commit})
+		return
+
+	case schema.KindBool:
+		rv.SetBool(!rv.Bool())
+
+	case schema.KindEnumList:
+		e.chooseOption(it.label, schema.EnumListOptions(f.Tag.Get("yenum")), int(rv.Int()), func(idx int) {
+			rv.SetInt(int64(idx))
+			e.commit(it)
+		})
+		return
+
+	case schema.KindEnumString:
+		opts, ok := schema.EnumStringOptionsFor(it.owner, f.Name, f.Tag)
+		if !ok || schema.EnumEditable(f.Tag) {
+			e.prompt(it)
+			return
+		}
+		current := 0
+		for i, opt := range opts {
+			if opt == rv.String() {
+				current = i
+			}
+		}
+		e.chooseOption(it.label, opts, current, func(idx int) {
+			rv.SetString(opts[idx])
+			e.commit(it)
+		})
+		return
+
+	case schema.KindStruct, schema.KindTabGroup, schema.KindGroupBox, schema.KindOneOf:
+		e.push(&page{
+			title:   it.label,
+			items:   func() []item { return itemsFor(f, rv) },
+			onClose: it.commit,
+		})
+		return
+
+	case schema.KindSlice, schema.KindArray:
+		e.push(e.listPage(it))
+		return
+
+	case schema.KindMap:
+		e.push(e.mapPage(it))
+		return
+
+	case schema.KindHeader:
+		return
+
+	case schema.KindFixed:
+		e.message = it.label + " can't be changed here."
+		return
+
+	case schema.KindCustom:
+		if !schema.IsText(f.Type) {
+			e.message = it.label + " can't be changed here."
+			return
+		}
+		e.prompt(it)
+		return
+
+	default:
+		e.prompt(it)
+		return
+	}
+
+	e.commit(it)
+}
+
+// chooseOption shows a page to choose one of the options.
+func (e *editor) chooseOption(title string, opts []string, current int, fn func(idx int)) {
+	e.push(&page{
+		title: title,
+		items: func() []item {
+			ret := make([]item, 0, len(opts))
+			for _, opt := range opts {
+				ret = append(ret, item{label: opt})
+			}
+			return ret
+		},
+		choose: fn,
+		cursor: current,
+	})
+}
+
+// chooseOneOf shows a page to select the option of a OneOf.
+func (e *editor) chooseOneOf(it *item) {
+	rv := it.rv
+	options := it.field.FormFields()
+
+	var labels []string
+	current := 0
+	for i, option := range options {
+		labels = append(labels, option.Label)
+		if option.Name == rv.Field(0).String() {
+			current = i
+		}
+	}
+
+	e.chooseOption(it.label, labels, current, func(idx int) {
+		option := options[idx]
+		if option.Name != rv.Field(0).String() {
+			schema.SelectOneOf(rv, option.Index)
+		}
+
+		if ptr := rv.Field(option.Index); ptr.Kind() == reflect.Pointer && ptr.IsNil() {
+			schema.Allocate(ptr)
+		}
+		e.commit(it)
+	})
+}
+
+// prompt edits a text value on the bottom line of the screen.
+func (e *editor) prompt(it *item) {
+	f := it.field
+
+	hint := ""
+	if len(f.Factors) > 0 {
+		var units []string
+		for _, fac := range f.Factors {
+			units = append(units, fac.Label)
+		}
+		hint = "Units: " + strings.Join(units, ", ")
+	} else if len(f.Flags) > 0 {
+		var names []string
+		for _, fl := range f.Flags {
+			names = append(names, fl.Label)
+		}
+		hint = "Flags, separated by |: " + strings.Join(names, ", ")
+	}
+
+	initial := schema.FormatText(it.rv, f.Tag)
+	if f.Secret {
+		initial = ""
+	}
+
+	text, ok := e.readLine(it.label, initial, hint, f.Secret)
+	if !ok {
+		return
+	}
+
+	err := schema.ParseTextFor(it.owner, f.Name, it.rv, f.Tag, text)
+	if err != nil {
+		e.message = "Error: " + err.Error()
+		return
+	}
+
+	e.commit(it)
+}
+
+// readLine reads a line of text, showing the rest of the page above it.
+func (e *editor) readLine(label, initial, hint string, secret bool) (string, bool) {
+	p := e.stack[len(e.stack)-1]
+	items := p.items()
+
+	buf := []rune(initial)
+	for {
+		e.draw(p, items)
+
+		var line bytes.Buffer
+		if hint != "" {
+			line.WriteString("\x1b[2m" + hint + "\x1b[0m\r\n")
+		}
+		line.WriteString(label + ": ")
+		if secret {
+			line.WriteString(strings.Repeat("*", len(buf)))
+		} else {
+			line.WriteString(string(buf))
+		}
+		e.write(line.Bytes())
+		if e.err != nil {
+			return "", false
+		}
+
+		k := readKey(e.in)
+		switch k.code {
+		case keyEnter:
+			return string(buf), true
+		case keyEscape, keyInterrupt, keyEOF:
+			return "", false
+		case keyBackspace:
+			if len(buf) > 0 {
+				buf = buf[:len(buf)-1]
+			}
+		case keyClearLine:
+			buf = buf[:0]
+		case keyRune:
+			buf = append(buf, k.r)
+		}
+	}
+}
+
+func (e *editor) write(b []byte) {
+	if e.err == nil {
+		_, e.err = e.out.Write(b)
+	}
+}
+
+// draw clears the screen and shows the page.
+func (e *editor) draw(p *page, items []item) {
+	var b bytes.Buffer
+
+	b.WriteString("\x1b[H\x1b[2J")
+	b.WriteString("\x1b[1m" + e.title + "\x1b[0m\r\n")
+
+	if len(e.stack) > 1 {
+		var crumbs []string
+		for _, parent := range e.stack[1:] {
+			crumbs = append(crumbs, parent.title)
+		}
+		b.WriteString("\x1b[2m" + strings.Join(crumbs, " › ") + "\x1b[0m\r\n")
+	}
+	b.WriteString("\r\n")
+
+	width := 0
+	for _, it := range items {
+		if !it.header && len(it.label) > width {
+			width = len(it.label)
+		}
+	}
+
+	for i, it := range items {
+		if it.header {
+			b.WriteString("\r\n\x1b[1m" + it.label + "\x1b[0m\r\n")
+			continue
+		}
+
+		line := it.label
+		if it.field != nil {
+			line = fmt.Sprintf("%-*s  %s", width, it.label, summary(it.field, it.rv))
+		}
+
+		if i == p.cursor {
+			b.WriteString("\x1b[7m> " + line + "\x1b[0m\r\n")
+		} else {
+			b.WriteString("  " + line + "\r\n")
+		}
+	}
+	if len(items) == 0 {
+		b.WriteString("  (empty)\r\n")
+	}
+	b.WriteString("\r\n")
+
+	if p.cursor < len(items) && items[p.cursor].field != nil && items[p.cursor].field.Help != "" {
+		b.WriteString(items[p.cursor].field.Help + "\r\n")
+	}
+	if e.message != "" {
+		b.WriteString("\x1b[31m" + e.message + "\x1b[0m\r\n")
+	}
+
+	keys := "↑/↓ move  Enter edit  Esc back  s save  Ctrl+C quit"
+	if p.add != nil {
+		keys += "  a add  d delete"
+	}
+	b.WriteString("\x1b[2m" + keys + "\x1b[0m\r\n")
+
+	e.write(b.Bytes())
+}
@@ -0,0 +1,129 @@
+package termconfig
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testOptional struct {
+	Level int
+}
+
+func (t *testOptional) Reset() {
+	t.Level = 3
+}
+
+type testConfig struct {
+	Name     string `ylabel:"Display name"`
+	Debug    bool
+	Timeout  time.Duration
+	Peers    []string
+	Labels   map[string]int
+	Optional *testOptional
+	Network  struct {
+		Port int
+	}
+}
+
+const (
+	up    = "\x1b[A"
+	down  = "\x1b[B"
+	enter = "\r"
+	esc   = "\x1b"
+)
+
+func TestEdit(t *testing.T) {
+	cfg := testConfig{Name: "old", Peers: []string{"a", "b"}, Labels: map[string]int{"x": 1}}
+
+	script := strings.Join([]string{
+		enter, "\x15new", enter, // Name: clear line and type
+		down, enter, // Debug: toggle
+		down, enter, "\x15", "90s", enter, // Timeout
+		down, enter, "d", "a", enter, "c", enter, esc, // Peers: delete "a", add "c"
+		down, enter, enter, "\x157", enter, "n", "\x15y", enter, "a", "z", enter, esc, // Labels: x=7, rename to y, add z
+		down, enter, esc, // Optional: allocate and reset
+		down, enter, enter, "\x158080", enter, esc, // Network.Port
+		"s",
+	}, "")
+
+	var out bytes.Buffer
+	saved, err := Edit(&cfg, "Settings", strings.NewReader(script), &out)
+	if err != nil || !saved {
+		t.Fatalf("Edit: got %v, %v", saved, err)
+	}
+
+	if cfg.Name != "new" || !cfg.Debug || cfg.Timeout != 90*time.Second || cfg.Network.Port != 8080 {
+		t.Errorf("Edit: got %#v", cfg)
+	}
+	if !reflect.DeepEqual(cfg.Peers, []string{"b", "c"}) {
+		t.Errorf("Peers: got %v", cfg.Peers)
+	}
+	if !reflect.DeepEqual(cfg.Labels, map[string]int{"y": 7, "z": 0}) {
+		t.Errorf("Labels: got %v", cfg.Labels)
+	}
+	if cfg.Optional == nil || cfg.Optional.Level != 3 {
+		t.Errorf("Optional: got %#v", cfg.Optional)
+	}
+
+	if !strings.Contains(out.String(), "Display name") {
+		t.Errorf("Edit: expected output to contain the label")
+	}
+}
+
+func TestEditCancel(t *testing.T) {
+	cfg := testConfig{Name: "old", Peers: []string{"a"}}
+
+	// Edit, then quit without saving
+	script := enter + "\x15new" + enter + down + down + down + enter + "d" + esc + "q"
+
+	var out bytes.Buffer
+	saved, err := Edit(&cfg, "Settings", strings.NewReader(script), &out)
+	if err != nil || saved {
+		t.Fatalf("Edit: got %v, %v", saved, err)
+	}
+
+	if cfg.Name != "old" || len(cfg.Peers) != 1 {
+		t.Errorf("Edit: expected no changes, got %#v", cfg)
+	}
+}
+
+func TestEditInvalid(t *testing.T) {
+	cfg := testConfig{}
+
+	// Invalid values show an error and are not applied
+	script := down + down + enter + "soon" + enter + "s"
+
+	var out bytes.Buffer
+	saved, err := Edit(&cfg, "Settings", strings.NewReader(script), &out)
+	if err != nil || !saved {
+		t.Fatalf("Edit: got %v, %v", saved, err)
+	}
+
+	if cfg.Timeout != 0 {
+		t.Errorf("Timeout: got %v", cfg.Timeout)
+	}
+	if !strings.Contains(out.String(), "Error: invalid unit") {
+		t.Errorf("Edit: expected an error message")
+	}
+}
+
+func TestEditOptionPage(t *testing.T) {
+	// OneOf and enum fields choose from a page of options, which have no field
+	chosen := -1
+	e := editor{in: bufio.NewReader(strings.NewReader("c" + "r" + down + enter + "s")), out: &bytes.Buffer{}}
+	e.push(&page{title: "Settings", items: func() []item { return nil }})
+	e.chooseOption("Type", []string{"Memory", "Disk"}, 0, func(idx int) { chosen = idx })
+
+	// Pointer shortcuts do nothing on a page of options
+	saved, err := e.run()
+	if err != nil || !saved {
+		t.Fatalf("run: got %v, %v", saved, err)
+	}
+	if chosen != 1 {
+		t.Errorf("chosen: got %d", chosen)
+	}
+}
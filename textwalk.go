@@ -0,0 +1,202 @@
+package autoconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mappu/autoconfig/schema"
+)
+
+// textLeafKind is the type of a textLeaf.
+type textLeafKind int
+
+const (
+	textLeafValue textLeafKind = iota // A single value, see schema.IsText
+	textLeafSlice                     // A slice of single values
+	textLeafMap                       // A map with single value keys and values
+	textLeafOneOf                     // The selected option of a OneOf
+)
+
+// textLeaf is a field in the struct tree that can be set from a string. It is
+// shared by BindFlags and LoadFromEnv.
+//
+// Each leaf finds its target value with a pair of functions: Resolve() allocates
+// any nil pointers along the path, and is used when the value is set. Peek()
+// never allocates, and returns an invalid reflect.Value if there is a nil
+// pointer along the path.
+type textLeaf struct {
+	Kind    textLeafKind
+	Field   reflect.StructField // For a OneOf, this is the OneOf field itself
+	Type    reflect.Type        // Target type, with pointers removed. For a OneOf, this is the containing struct
+	Path    []string            // Struct field names from the root
+	Depth   int                 // Number of embedded structs that the field is promoted through
+	Resolve func() reflect.Value
+	Peek    func() reflect.Value
+}
+
+// walkTextLeaves visits every text leaf in the struct type, in the same order
+// as handle_struct. Types that cannot be represented as text are skipped.
+func walkTextLeaves(obj reflect.Type, resolve, peek func() reflect.Value, path []string, fn func(leaf textLeaf)) {
+
+	nf := obj.NumField()
+	for i := 0; i < nf; i++ {
+		ff := obj.Field(i)
+		idx := i
+
+		if i == 0 && ff.Type == reflect.TypeOf(OneOf("")) {
+			walkTextLeavesOneOf(obj, resolve, peek, path, fn)
+			return
+		}
+
+		if !ff.IsExported() {
+			continue
+		}
+
+		fieldResolve := func() reflect.Value { return resolve().Field(idx) }
+		fieldPeek := func() reflect.Value {
+			rv := peek()
+			if !rv.IsValid() {
+				return rv
+			}
+			return rv.Field(idx)
+		}
+
+		fieldPath, fieldFn := appendPath(path, ff.Name), fn
+		if ff.Anonymous && ff.Type.Kind() == reflect.Struct && !schema.IsText(ff.Type) {
+			// Embedded structs are rendered inline, so they don't add a path segment
+			fieldPath = path
+			fieldFn = func(leaf textLeaf) {
+				leaf.Depth++
+				fn(leaf)
+			}
+		}
+
+		walkTextLeavesAny(ff, ff.Type, fieldResolve, fieldPeek, fieldPath, fieldFn)
+	}
+}
+
+// appendPath appends to a path without aliasing the parent path.
+func appendPath(path []string, name string) []string {
+	ret := make([]string, 0, len(path)+1)
+	ret = append(ret, path...)
+	return append(ret, name)
+}
+
+func walkTextLeavesAny(ff reflect.StructField, t reflect.Type, resolve, peek func() reflect.Value, path []string, fn func(leaf textLeaf)) {
+
+	if schema.IsText(t) {
+		fn(textLeaf{Kind: textLeafValue, Field: ff, Type: t, Path: path, Resolve: resolve, Peek: peek})
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		elemResolve := func() reflect.Value {
+			ptr := resolve()
+			if ptr.IsNil() {
+				schema.Allocate(ptr)
+			}
+			return ptr.Elem()
+		}
+		elemPeek := func() reflect.Value {
+			ptr := peek()
+			if !ptr.IsValid() || ptr.IsNil() {
+				return reflect.Value{}
+			}
+			return ptr.Elem()
+		}
+		walkTextLeavesAny(ff, t.Elem(), elemResolve, elemPeek, path, fn)
+
+	case reflect.Struct:
+		if t.NumField() == 0 {
+			return // Header, TabGroup, empty struct
+		}
+		walkTextLeaves(t, resolve, peek, path, fn)
+
+	case reflect.Slice:
+		if schema.IsText(t.Elem()) {
+			fn(textLeaf{Kind: textLeafSlice, Field: ff, Type: t, Path: path, Resolve: resolve, Peek: peek})
+		}
+
+	case reflect.Map:
+		if schema.IsText(t.Key()) && schema.IsText(t.Elem()) {
+			fn(textLeaf{Kind: textLeafMap, Field: ff, Type: t, Path: path, Resolve: resolve, Peek: peek})
+		}
+
+	default:
+		// Unsupported as text
+	}
+}
+
+func walkTextLeavesOneOf(obj reflect.Type, resolve, peek func() reflect.Value, path []string, fn func(leaf textLeaf)) {
+
+	selectorPath := path
+	if len(selectorPath) == 0 {
+		selectorPath = []string{obj.Field(0).Name}
+	}
+
+	fn(textLeaf{Kind: textLeafOneOf, Field: obj.Field(0), Type: obj, Path: selectorPath, Resolve: resolve, Peek: peek})
+
+	for i := 1; i < obj.NumField(); i++ {
+		ff := obj.Field(i)
+		idx := i
+
+		// Setting any value inside an option also selects that option
+		optionResolve := func() reflect.Value {
+			rv := resolve()
+			if rv.Field(0).String() != obj.Field(idx).Name {
+				schema.SelectOneOf(rv, idx)
+			}
+			return rv.Field(idx)
+		}
+
+		optionPeek := func() reflect.Value {
+			rv := peek()
+			if !rv.IsValid() || rv.Field(0).String() != obj.Field(idx).Name {
+				return reflect.Value{}
+			}
+			return rv.Field(idx)
+		}
+
+		walkTextLeavesAny(ff, ff.Type, optionResolve, optionPeek, appendPath(path, ff.Name), fn)
+	}
+}
+
+// appendText parses a string and appends it to the slice.
+func appendText(rv reflect.Value, tag reflect.StructTag, s string) error {
+	elem := reflect.New(rv.Type().Elem()).Elem()
+	err := schema.ParseText(elem, tag, s)
+	if err != nil {
+		return err
+	}
+
+	rv.Set(reflect.Append(rv, elem))
+	return nil
+}
+
+// insertMapText parses a "key=value" string and inserts it into the map.
+func insertMapText(rv reflect.Value, tag reflect.StructTag, s string) error {
+	kStr, vStr, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("expected key=value, got %q", s)
+	}
+
+	mk := reflect.New(rv.Type().Key()).Elem()
+	err := schema.ParseText(mk, tag, kStr)
+	if err != nil {
+		return err
+	}
+
+	mv := reflect.New(rv.Type().Elem()).Elem()
+	err = schema.ParseText(mv, tag, vStr)
+	if err != nil {
+		return err
+	}
+
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMap(rv.Type()))
+	}
+	rv.SetMapIndex(mk, mv)
+	return nil
+}
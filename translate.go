@@ -0,0 +1,40 @@
+package autoconfig
+
+import (
+	"github.com/mappu/autoconfig/schema"
+	qt "github.com/mappu/miqt/qt6"
+)
+
+// Translator translates the text shown in a form, including field labels,
+// `yhelp` text, enum options, Factor units and built-in text. See
+// schema.Translator for the translation contexts.
+type Translator = schema.Translator
+
+// SetTranslator configures the translator for all text shown in a form, or
+// nil to show the English source text.
+// This is package-global and not threadsafe.
+func SetTranslator(t Translator) {
+	schema.SetTranslator(t)
+}
+
+// QtTranslator is a Translator that uses QCoreApplication::translate, so that
+// translations can be loaded from .qm files with a QTranslator.
+type QtTranslator struct{}
+
+func (QtTranslator) Translate(context, text string) string {
+	return qt.QCoreApplication_Translate(context, text)
+}
+
+// tr translates built-in text.
+func tr(text string) string {
+	return schema.Translate(schema.ContextUI, text)
+}
+
+// trAll translates each of the texts.
+func trAll(context string, texts []string) []string {
+	ret := make([]string, 0, len(texts))
+	for _, text := range texts {
+		ret = append(ret, schema.Translate(context, text))
+	}
+	return ret
+}
@@ -32,8 +32,16 @@ func (AddressPort) Render(area *qt.QFormLayout, rv *reflect.Value, tag reflect.S
 	port.SetValue(int(rv.Field(1).Int())) // Port
 	hbox.AddWidget(port.QWidget)
 
+	form := activeForm
+	addr.SetReadOnly(form.readOnly)
+	port.SetReadOnly(form.readOnly)
+	addr.OnEditingFinished(func() { form.edited(editText(label)) })
+	port.OnEditingFinished(func() { form.edited(editText(label)) })
+	form.addCompleter(addr, rv, tag)
+
 	hboxWidget := qt.NewQWidget(area.ParentWidget())
 	hboxWidget.SetLayout(hbox.QLayout)
+	setAccessibleParts(hboxWidget, label, accessiblePart{addr.QWidget, tr("%s address")}, accessiblePart{port.QWidget, tr("%s port")})
 	addRow(area, label, hboxWidget)
 
 	return func() {
@@ -9,6 +9,9 @@ import (
 func handle_bool(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string) SaveFunc {
 	rbtn := qt.NewQCheckBox3(label)
 	rbtn.SetChecked(rv.Bool())
+	form := activeForm
+	rbtn.SetEnabled(!form.readOnly)
+	rbtn.OnToggled(func(bool) { form.edited(editText(label)) })
 
 	// Don't use addRow() helper since we deliberately want this to appear
 	// in the 2nd column
@@ -17,12 +17,12 @@ func handle_byte_slice(area *qt.QFormLayout, rv *reflect.Value, tag reflect.Stru
 	refreshDisplay := func() {
 		content := rv.Bytes()
 		if len(content) == 0 {
-			display.SetText("Empty content")
+			display.SetText(tr("Empty content"))
 			return
 		}
 
 		mimeType := http.DetectContentType(content)
-		display.SetText(fmt.Sprintf("%s (%d bytes)", mimeType, len(content)))
+		display.SetText(fmt.Sprintf(tr("%s (%d bytes)"), mimeType, len(content)))
 	}
 	display.SetSizePolicy2(qt.QSizePolicy__MinimumExpanding, qt.QSizePolicy__Minimum)
 	refreshDisplay()
@@ -30,19 +30,31 @@ func handle_byte_slice(area *qt.QFormLayout, rv *reflect.Value, tag reflect.Stru
 
 	editBtn := qt.NewQToolButton2()
 
+	form := activeForm
+
 	menu := qt.NewQMenu(editBtn.QWidget)
 
-	actionEditText := menu.AddActionWithText("Edit as text...")
+	actionEditText := menu.AddActionWithText(tr("Edit as text..."))
+	if form.readOnly {
+		actionEditText.SetText(tr("View as text..."))
+	}
 	actionEditText.OnTriggered(func() {
 		mlString := MultiLineString(rv.Bytes())
+		if form.readOnly {
+			OpenDialog(&mlString, editBtn.QWidget, label, func() {}, WithReadOnly())
+			return
+		}
+
 		OpenDialog(&mlString, editBtn.QWidget, label, func() {
 			// Copy content from temp back into rv
 			rv.SetBytes([]byte(mlString))
 			refreshDisplay()
+			form.edited(editText(label))
 		})
 	})
 
-	actionLoadFromFile := menu.AddActionWithText("Import from file...")
+	actionLoadFromFile := menu.AddActionWithText(tr("Import from file..."))
+	actionLoadFromFile.SetEnabled(!form.readOnly)
 	actionLoadFromFile.OnTriggered(func() {
 		filePath := qt.QFileDialog_GetOpenFileNameWithParent(editBtn.QWidget)
 		if filePath == "" {
@@ -51,17 +63,18 @@ func handle_byte_slice(area *qt.QFormLayout, rv *reflect.Value, tag reflect.Stru
 
 		content, err := os.ReadFile(filePath)
 		if err != nil {
-			qt.QMessageBox_Warning(editBtn.QWidget, "Error loading file content", err.Error())
+			qt.QMessageBox_Warning(editBtn.QWidget, tr("Error loading file content"), err.Error())
 			return
 		}
 
 		rv.SetBytes(content)
 		refreshDisplay()
+		form.edited(editText(label))
 	})
 
 	menu.AddSeparator()
 
-	actionExport := menu.AddActionWithText("Export to file...")
+	actionExport := menu.AddActionWithText(tr("Export to file..."))
 	actionExport.OnTriggered(func() {
 		filePath := qt.QFileDialog_GetSaveFileNameWithParent(editBtn.QWidget)
 		if filePath == "" {
@@ -70,7 +83,7 @@ func handle_byte_slice(area *qt.QFormLayout, rv *reflect.Value, tag reflect.Stru
 
 		err := os.WriteFile(filePath, rv.Bytes(), 0644)
 		if err != nil {
-			qt.QMessageBox_Warning(editBtn.QWidget, "Error loading file content", err.Error())
+			qt.QMessageBox_Warning(editBtn.QWidget, tr("Error loading file content"), err.Error())
 			return
 		}
 
@@ -82,7 +95,7 @@ func handle_byte_slice(area *qt.QFormLayout, rv *reflect.Value, tag reflect.Stru
 	editBtn.SetPopupMode(qt.QToolButton__InstantPopup)
 	hbox.AddWidget(editBtn.QWidget)
 
-	addRowLayout(area, label, hbox.QLayout)
+	addRowLayout(area, label, hbox.QLayout, editBtn.QWidget)
 
 	return func() {
 		// Edit function has already mutated the value
@@ -34,8 +34,15 @@ func handle_complex(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructT
 	imp_float.SetSuffix(" i")
 	hbox.AddWidget(imp_float.QWidget)
 
+	form := activeForm
+	rep_float.SetReadOnly(form.readOnly)
+	imp_float.SetReadOnly(form.readOnly)
+	rep_float.OnEditingFinished(func() { form.edited(editText(label)) })
+	imp_float.OnEditingFinished(func() { form.edited(editText(label)) })
+
 	hboxWidget := qt.NewQWidget(area.ParentWidget())
 	hboxWidget.SetLayout(hbox.QLayout)
+	setAccessibleParts(hboxWidget, label, accessiblePart{rep_float.QWidget, tr("%s real part")}, accessiblePart{imp_float.QWidget, tr("%s imaginary part")})
 	addRow(area, label, hboxWidget)
 
 	return func() {
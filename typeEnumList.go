@@ -2,8 +2,8 @@ package autoconfig
 
 import (
 	"reflect"
-	"strings"
 
+	"github.com/mappu/autoconfig/schema"
 	qt "github.com/mappu/miqt/qt6"
 )
 
@@ -16,8 +16,11 @@ func (EnumList) Render(area *qt.QFormLayout, rv *reflect.Value, tag reflect.Stru
 	enumOpts, _ := tag.Lookup("yenum")
 
 	rcombo := qt.NewQComboBox2()
-	rcombo.AddItems(strings.Split(enumOpts, `;;`)) // Same separator as Qt filter (yfilter)
+	rcombo.AddItems(trAll(schema.ContextEnum, schema.EnumListOptions(enumOpts)))
 	rcombo.SetCurrentIndex(int(rv.Int()))
+	form := activeForm
+	rcombo.SetEnabled(!form.readOnly)
+	rcombo.OnActivated(func(int) { form.edited(editText(label)) })
 
 	addRow(area, label, rcombo.QWidget)
 
@@ -2,60 +2,115 @@ package autoconfig
 
 import (
 	"reflect"
+	"strings"
 
+	"github.com/mappu/autoconfig/schema"
 	qt "github.com/mappu/miqt/qt6"
 )
 
 // EnumString allows choosing from a dropdown.
 // First, prefill the available options via the SetEnumStringOptions function,
 // and then pass your global keyname in the `yenum` struct tag.
+// With the `yeditable` struct tag, other values may also be typed in, and the
+// options are only suggestions.
+// To compute the options when the form is shown, use SetEnumStringFunc or
+// implement EnumProvider on the struct.
 type EnumString string
 
-var enumStringOpts map[string][]string
+// EnumProvider is implemented by configurable structs that compute the options
+// of their EnumString fields. See schema.EnumProvider for details.
+type EnumProvider = schema.EnumProvider
 
 // SetEnumStringOptions configures the list of allowed options for the given key
 // when used with the autoconfig.EnumString type.
-// This is package-global and not threadsafe.
+// This is package-global and threadsafe.
 // To unregister a key, set the 'options' to nil.
 func SetEnumStringOptions(key string, options []string) {
-	if enumStringOpts == nil {
-		enumStringOpts = make(map[string][]string)
-	}
+	schema.SetEnumStringOptions(key, options)
+}
 
-	if options == nil {
-		delete(enumStringOpts, key)
-	} else {
-		enumStringOpts[key] = options
-	}
+// SetEnumStringFunc configures a function that computes the options for the
+// given key when the form is shown, from the struct that has the field.
+// The options are computed again after each edit in the form, e.g. to list
+// the profiles from another field, and by Editor.RefreshOptions.
+// To unregister a key, set the 'fn' to nil.
+func SetEnumStringFunc(key string, fn func(parent any) []string) {
+	schema.SetEnumStringFunc(key, fn)
 }
 
 func (EnumString) Render(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string) SaveFunc {
-	enumKey, _ := tag.Lookup("yenum")
+	form := activeForm
+	owner, field := form.owner, form.path[strings.LastIndex(form.path, ".")+1:]
 
-	opts, ok := enumStringOpts[enumKey]
-	if !ok {
-		// programmer error
-		panic("EnumString: key '" + enumKey + "' not registered in SetEnumListOptions")
-	}
+	opts, ok := schema.EnumStringOptionsFor(owner, field, tag)
+	editable := !ok || schema.EnumEditable(tag) // Unknown keys allow any value
 
-	currentIndex := 0
-	{
-		currentString := rv.String()
-		for i, opt := range opts {
-			if opt == currentString {
-				currentIndex = i
-				break
-			}
-		}
+	rcombo := qt.NewQComboBox2()
+	rcombo.SetEditable(editable)
+	setEnumStringItems(rcombo, opts, rv.String(), editable)
+	rcombo.SetEnabled(!form.readOnly)
+	rcombo.OnActivated(func(int) { form.edited(editText(label)) })
+
+	if editable {
+		rcombo.SetInsertPolicy(qt.QComboBox__NoInsert) // Other values are only saved, not added as options
+		rcombo.LineEdit().OnEditingFinished(func() { form.edited(editText(label)) })
+		form.addCompleter(rcombo.LineEdit(), rv, tag, trAll(schema.ContextEnum, opts)...)
 	}
 
-	rcombo := qt.NewQComboBox2()
-	rcombo.AddItems(opts)
-	rcombo.SetCurrentIndex(currentIndex)
+	// Options from a provider may depend on other fields
+	form.addRefresher(func() {
+		newOpts, _ := schema.EnumStringOptionsFor(owner, field, tag)
+		if reflect.DeepEqual(newOpts, opts) {
+			return
+		}
+		opts = newOpts
+		setEnumStringItems(rcombo, opts, enumStringValue(rcombo, editable), editable)
+	})
 
 	addRow(area, label, rcombo.QWidget)
 
 	return func() {
-		rv.SetString(opts[rcombo.CurrentIndex()])
+		rv.SetString(enumStringValue(rcombo, editable))
+	}
+}
+
+// setEnumStringItems fills the dropdown with the options, and selects the
+// current value. A current value that is not one of the options is kept as an
+// extra item, so that it isn't changed unless the user chooses another.
+func setEnumStringItems(rcombo *qt.QComboBox, opts []string, current string, editable bool) {
+	rcombo.Clear()
+
+	currentIndex := -1
+	for i, opt := range opts {
+		rcombo.AddItem3(schema.Translate(schema.ContextEnum, opt), qt.NewQVariant11(opt))
+		if opt == current {
+			currentIndex = i
+		}
+	}
+
+	switch {
+	case currentIndex != -1:
+		rcombo.SetCurrentIndex(currentIndex)
+	case editable:
+		rcombo.SetEditText(current)
+	case current != "" || len(opts) == 0:
+		rcombo.AddItem3(current, qt.NewQVariant11(current))
+		rcombo.SetCurrentIndex(rcombo.Count() - 1)
+	default:
+		rcombo.SetCurrentIndex(0) // Not set yet, so use the first option
+	}
+}
+
+// enumStringValue gets the option that is selected in the dropdown, or the
+// text that was typed in, if editable.
+func enumStringValue(rcombo *qt.QComboBox, editable bool) string {
+	if !editable {
+		return rcombo.CurrentData().ToString()
+	}
+
+	text := rcombo.CurrentText()
+	if idx := rcombo.FindText(text); idx != -1 {
+		return rcombo.ItemData(idx).ToString() // Untranslated
 	}
+	return text
 }
@@ -22,19 +22,27 @@ func (ExistingDirectory) Render(area *qt.QFormLayout, rv *reflect.Value, tag ref
 	rline.SetText(rv.String())
 	hbox.AddWidget(rline.QWidget)
 
+	form := activeForm
+	rline.SetReadOnly(form.readOnly)
+	rline.OnEditingFinished(func() { form.edited(editText(label)) })
+	form.addCompleter(rline, rv, tag)
+
 	browseBtn := qt.NewQPushButton2()
 	setIcon(browseBtn.QAbstractButton, "folder-open", "Browse...", "Browse...")
+	browseBtn.SetEnabled(!form.readOnly)
 	hbox.AddWidget(browseBtn.QWidget)
 
 	browseBtn.OnClicked(func() {
-		openDir := qt.QFileDialog_GetExistingDirectory3(browseBtn.QWidget, "Select a database directory...", rline.Text())
+		openDir := qt.QFileDialog_GetExistingDirectory3(browseBtn.QWidget, tr("Select a database directory..."), rline.Text())
 		if openDir != "" {
 			rline.SetText(openDir)
+			form.edited(editText(label))
 		}
 	})
 
 	hboxWidget := qt.NewQWidget(area.ParentWidget())
 	hboxWidget.SetLayout(hbox.QLayout)
+	setAccessibleParts(hboxWidget, label, accessiblePart{rline.QWidget, "%s"})
 	addRow(area, label, hboxWidget)
 
 	return func() {
@@ -24,12 +24,18 @@ func (*ExistingFile) Render(area *qt.QFormLayout, rv *reflect.Value, tag reflect
 	rline.SetText(rv.String())
 	hbox.AddWidget(rline.QWidget)
 
+	form := activeForm
+	rline.SetReadOnly(form.readOnly)
+	rline.OnEditingFinished(func() { form.edited(editText(label)) })
+	form.addCompleter(rline, rv, tag)
+
 	browseBtn := qt.NewQPushButton2()
 	setIcon(browseBtn.QAbstractButton, "document-open", "Browse...", "Browse...")
 
+	browseBtn.SetEnabled(!form.readOnly)
 	hbox.AddWidget(browseBtn.QWidget)
 
-	filter := "All files (*)"
+	filter := tr("All files (*)")
 	if useFilter, ok := tag.Lookup("yfilter"); ok {
 		filter = useFilter
 	}
@@ -37,14 +43,16 @@ func (*ExistingFile) Render(area *qt.QFormLayout, rv *reflect.Value, tag reflect
 	browseBtn.OnClicked(func() {
 		startDir := filepath.Dir(rline.Text())
 
-		openPath := qt.QFileDialog_GetOpenFileName4(browseBtn.QWidget, "Select a database file...", startDir, filter)
+		openPath := qt.QFileDialog_GetOpenFileName4(browseBtn.QWidget, tr("Select a database file..."), startDir, filter)
 		if openPath != "" {
 			rline.SetText(openPath)
+			form.edited(editText(label))
 		}
 	})
 
 	hboxWidget := qt.NewQWidget(area.ParentWidget())
 	hboxWidget.SetLayout(hbox.QLayout)
+	setAccessibleParts(hboxWidget, label, accessiblePart{rline.QWidget, "%s"})
 	addRow(area, label, hboxWidget)
 
 	return func() {
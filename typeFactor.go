@@ -3,11 +3,9 @@ package autoconfig
 import (
 	"math"
 	"reflect"
-	"strconv"
-	"strings"
-	"time"
 
 	"github.com/mappu/autoconfig/qspinbox"
+	"github.com/mappu/autoconfig/schema"
 	qt "github.com/mappu/miqt/qt6"
 )
 
@@ -34,54 +32,22 @@ import (
 // large factor)... Just don't do that.
 type Factor int64
 
-type factor struct {
-	Divisor int64
-	Label   string
-}
-
 func (Factor) Render(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string) SaveFunc {
-
-	// Parse all factors out
-
-	yfactor := tag.Get("yfactor")
-	if len(yfactor) == 0 {
+	factors, ok := schema.Factors(rv.Type(), tag)
+	if !ok {
 		// Factor without yfactor tag is just an int64
 		return handle_int(area, rv, tag, label)
 	}
 
-	parts := strings.Split(yfactor, `;;`)
-	if len(parts)%2 != 0 {
-		panic("autoconfig.Factor expects yfactor to have an even number of properties") // Programmer error
-	}
-
-	factors := make([]factor, 0, len(parts)/2)
-
-	for i := 0; i < len(parts); i += 2 {
-		parse, err := strconv.ParseInt(parts[i], 10, 64)
-		if err != nil {
-			panic(err) // Programmer error
-		}
-
-		factors = append(factors, factor{parse, parts[i+1]})
-	}
-
 	return handle_factor_with(area, rv, tag, label, factors)
 }
 
 // handle_factor_with is the common helper for Factor-type inputs.
-func handle_factor_with(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string, factors []factor) SaveFunc {
+func handle_factor_with(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string, factors []schema.Factor) SaveFunc {
 
 	// Determine current factor for input value
 
-	rawValue := rv.Int()
-	initialFactorIdx := 0
-	for i := 0; i < len(factors); i += 1 {
-		reverseIdx := len(factors) - i - 1
-		if rawValue%factors[reverseIdx].Divisor == 0 {
-			initialFactorIdx = reverseIdx // OK, match
-			break
-		}
-	}
+	initialFactorIdx := schema.InitialFactor(factors, rv.Int())
 
 	// Construct
 
@@ -96,13 +62,20 @@ func handle_factor_with(area *qt.QFormLayout, rv *reflect.Value, tag reflect.Str
 
 	opts := qt.NewQComboBox2()
 	for _, fac := range factors {
-		opts.AddItem(fac.Label)
+		opts.AddItem(schema.Translate(schema.ContextUnit, fac.Label))
 	}
 	opts.SetCurrentIndex(initialFactorIdx)
 	hbox.AddWidget(opts.QWidget)
 
+	form := activeForm
+	rint.SetReadOnly(form.readOnly)
+	opts.SetEnabled(!form.readOnly)
+	rint.OnEditingFinished(func() { form.edited(editText(label)) })
+	opts.OnActivated(func(int) { form.edited(editText(label)) })
+
 	hboxWidget := qt.NewQWidget(area.ParentWidget())
 	hboxWidget.SetLayout(hbox.QLayout)
+	setAccessibleParts(hboxWidget, label, accessiblePart{rint.QWidget, "%s"}, accessiblePart{opts.QWidget, tr("%s unit")})
 	addRow(area, label, hboxWidget)
 
 	return func() {
@@ -118,44 +91,24 @@ func handle_factor_with(area *qt.QFormLayout, rv *reflect.Value, tag reflect.Str
 type Bytes int64
 
 func (Bytes) Render(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string) SaveFunc {
-	return handle_factor_with(area, rv, tag, label, []factor{
-		{1, "B"},
-		{1024, "KiB"},
-		{1024 * 1024, "MiB"},
-		{1024 * 1024 * 1024, "GiB"},
-		{1024 * 1024 * 1024 * 1024, "TiB"},
-		{1024 * 1024 * 1024 * 1024 * 1024, "PiB"},
-	})
+	factors, _ := schema.Factors(rv.Type(), tag)
+	return handle_factor_with(area, rv, tag, label, factors)
 }
 
 // MetricBytes is an int64 number of bytes. It uses factor-1000 and MB-style names.
 type MetricBytes int64
 
 func (MetricBytes) Render(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string) SaveFunc {
-	return handle_factor_with(area, rv, tag, label, []factor{
-		{1, "B"},
-		{1000, "KB"},
-		{1000 * 1000, "MB"},
-		{1000 * 1000 * 1000, "GB"},
-		{1000 * 1000 * 1000 * 1000, "TB"},
-		{1000 * 1000 * 1000 * 1000 * 1000, "PB"},
-	})
+	factors, _ := schema.Factors(rv.Type(), tag)
+	return handle_factor_with(area, rv, tag, label, factors)
 }
 
 // Bitrate is an int64 number of bits/sec. It uses factor-1024 and MB-style names.
 type Bitrate int64
 
 func (Bitrate) Render(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string) SaveFunc {
-	return handle_factor_with(area, rv, tag, label, []factor{
-		{1, "bit"},
-		{8, "B/s"},
-		{1 * 1024, "Kbit"},
-		{8 * 1024, "KB/s"},
-		{1 * 1024 * 1024, "MBit"},
-		{8 * 1024 * 1024, "MB/s"},
-		{1 * 1024 * 1024 * 1024, "GBit"},
-		{8 * 1024 * 1024 * 1024, "GB/s"},
-	})
+	factors, _ := schema.Factors(rv.Type(), tag)
+	return handle_factor_with(area, rv, tag, label, factors)
 }
 
 // Distance is an int64 number of microns.
@@ -176,27 +129,12 @@ const (
 )
 
 func (Distance) Render(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string) SaveFunc {
-	return handle_factor_with(area, rv, tag, label, []factor{
-		{int64(Micron), "microns"},
-		{int64(Millimeter), "mm"},
-		{int64(Centimeter), "cm"},
-		{int64(Inch), "inches"},
-		{int64(Foot), "feet"},
-		{int64(Yard), "yards"},
-		{int64(Meter), "m"},
-		{int64(Kilometer), "km"},
-		{int64(Mile), "miles"},
-	})
+	factors, _ := schema.Factors(rv.Type(), tag)
+	return handle_factor_with(area, rv, tag, label, factors)
 }
 
 // The Go stdlib time.Duration is an int64 number of nanoseconds.
 func handle_stdlibTimeDuration(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string) SaveFunc {
-	return handle_factor_with(area, rv, tag, label, []factor{
-		{int64(time.Nanosecond), "nsec"},  // x1
-		{int64(time.Microsecond), "μsec"}, // x1000
-		{int64(time.Millisecond), "ms"},   // x1000 x1000
-		{int64(time.Second), "seconds"},   // x1000 x1000 x1000
-		{int64(time.Minute), "minutes"},   // x1000 x1000 x1000 x60
-		{int64(time.Hour), "hours"},       // x1000 x1000 x1000 x60 x60
-	})
+	factors, _ := schema.Factors(rv.Type(), tag)
+	return handle_factor_with(area, rv, tag, label, factors)
 }
@@ -8,7 +8,7 @@ import (
 
 func handle_fixed(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string) SaveFunc {
 	rlabel := qt.NewQLabel2()
-	rlabel.SetText(formatValue(rv))
+	rlabel.SetText(formatValue(rv, tag))
 	addRow(area, label, rlabel.QWidget)
 	return func() {}
 }
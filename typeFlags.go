@@ -0,0 +1,85 @@
+package autoconfig
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/mappu/autoconfig/schema"
+	qt "github.com/mappu/miqt/qt6"
+)
+
+// Flags is a uint64 where each bit is a named option, shown as a checkbox.
+// The `yflags` tag can also be used on any other unsigned integer type.
+//
+// Use with the `yflags` tag, in the same syntax as `yfactor`, e.g.
+//
+//	yflags:"1;;Read;;2;;Write;;4;;Execute"
+//
+// Bits that are not named in the tag are kept unchanged. A mask with several
+// bits is checked only if all of them are set. A mask of 0 names the value
+// with no bits set, and has no checkbox.
+type Flags uint64
+
+func (Flags) Render(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string) SaveFunc {
+	if _, ok := schema.Flags(rv.Type(), tag); !ok {
+		// Flags without yflags tag is just a uint64
+		return handle_uint(area, rv, tag, label)
+	}
+
+	return handle_flags(area, rv, tag, label)
+}
+
+// flagsColumns is the number of checkboxes in each row.
+const flagsColumns = 3
+
+func handle_flags(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string) SaveFunc {
+	flags, _ := schema.Flags(rv.Type(), tag)
+	current := rv.Uint()
+
+	grid := qt.NewQGridLayout2()
+	form := activeForm
+
+	type checkbox struct {
+		mask uint64
+		btn  *qt.QCheckBox
+	}
+	var boxes []checkbox
+
+	for _, fl := range flags {
+		if fl.Mask == 0 {
+			continue // Only a name for no bits set
+		}
+
+		btn := qt.NewQCheckBox3(schema.Translate(schema.ContextEnum, fl.Label))
+		btn.SetChecked(current&fl.Mask == fl.Mask)
+		btn.SetEnabled(!form.readOnly)
+		btn.OnToggled(func(bool) { form.edited(editText(label)) })
+
+		grid.AddWidget2(btn.QWidget, len(boxes)/flagsColumns, len(boxes)%flagsColumns)
+		boxes = append(boxes, checkbox{fl.Mask, btn})
+	}
+
+	// Bits without a name are kept, and shown for information
+	known := schema.FlagsMask(flags)
+	if other := current &^ known; other != 0 {
+		otherLabel := qt.NewQLabel3(fmt.Sprintf("+ %#x", other))
+		otherLabel.SetToolTip(tr("Other bits, which are kept unchanged"))
+		grid.AddWidget2(otherLabel.QWidget, len(boxes)/flagsColumns, len(boxes)%flagsColumns)
+	}
+
+	var focus *qt.QWidget
+	if len(boxes) > 0 {
+		focus = boxes[0].btn.QWidget
+	}
+	addRowLayout(area, label, grid.QLayout, focus)
+
+	return func() {
+		val := rv.Uint() &^ known
+		for _, box := range boxes {
+			if box.btn.IsChecked() {
+				val |= box.mask
+			}
+		}
+		rv.SetUint(val)
+	}
+}
@@ -1,9 +1,9 @@
 package autoconfig
 
 import (
-	"math"
 	"reflect"
 
+	"github.com/mappu/autoconfig/schema"
 	qt "github.com/mappu/miqt/qt6"
 )
 
@@ -11,9 +11,10 @@ func handle_float(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag
 	rfloat := qt.NewQDoubleSpinBox2()
 
 	// By default, this is clamped to 100
-	// Just allow ~unlimited, even for float32
-	rfloat.SetMinimum(-math.MaxFloat64)
-	rfloat.SetMaximum(math.MaxFloat64)
+	// Just allow ~unlimited, even for float32, unless the ymin/ymax tags are set
+	min, max := schema.FloatBounds(tag)
+	rfloat.SetMinimum(min)
+	rfloat.SetMaximum(max)
 	rfloat.SetValue(rv.Float()) // After setting bounds, otherwise it gets clamped
 
 	if prefix := tag.Get("yprefix"); len(prefix) > 0 {
@@ -23,6 +24,10 @@ func handle_float(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag
 		rfloat.SetSuffix(suffix)
 	}
 
+	form := activeForm
+	rfloat.SetReadOnly(form.readOnly)
+	rfloat.OnEditingFinished(func() { form.edited(editText(label)) })
+
 	// This widget is also fixed to show two decimal places
 	// May want to allow customization from a struct tag?
 	addRow(area, label, rfloat.QWidget)
@@ -0,0 +1,67 @@
+package autoconfig
+
+import (
+	"reflect"
+
+	"github.com/mappu/autoconfig/schema"
+	qt "github.com/mappu/miqt/qt6"
+)
+
+// If a GroupBox is the first member in a struct, the struct rendering will
+// change to show all remaining fields inside a framed group box, titled with
+// the struct's label.
+//
+// To group some consecutive fields of a struct without a separate type, add the
+// same `ygroup:"Name"` tag to each of them instead.
+//
+// Add the `ycollapsed` tag (on the struct field, or on the first field of a
+// `ygroup`) to make the group collapsible. Use `ycollapsed:"true"` to start
+// collapsed, e.g. for advanced settings.
+type GroupBox struct{}
+
+func handle_struct_as_GroupBox(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string) SaveFunc {
+	fields := schema.FormFields(rv.Type(), activeForm.layout()...)
+
+	return addGroupBox(area, label, tag, func(inner *qt.QFormLayout) SaveFunc {
+		return handle_struct_fields(inner, rv, fields)
+	})
+}
+
+// addGroupBox adds a group box to the area, and calls fn to fill its form.
+func addGroupBox(area *qt.QFormLayout, title string, tag reflect.StructTag, fn func(inner *qt.QFormLayout) SaveFunc) SaveFunc {
+
+	box := qt.NewQGroupBox4(title, area.ParentWidget())
+	boxLayout := qt.NewQVBoxLayout(box.QWidget)
+
+	content := qt.NewQWidget(box.QWidget)
+	inner := qt.NewQFormLayout(content)
+	inner.SetContentsMargins(0, 0, 0, 0)
+	boxLayout.AddWidget(content)
+
+	collapsed, collapsible := tag.Lookup("ycollapsed")
+	if collapsible {
+		box.SetCheckable(true)
+		box.SetChecked(collapsed != "true")
+		content.SetVisible(box.IsChecked())
+		box.OnToggled(func(on bool) {
+			content.SetVisible(on)
+		})
+	}
+
+	var saver SaveFunc
+
+	form := activeForm
+	group := form.filterScope(area, title, func() {
+		saver = fn(inner)
+		area.AddRowWithWidget(box.QWidget)
+	})
+
+	// When searching, expand a collapsed group that has matches
+	group.reveal = func(query string) {
+		if collapsible && query != "" && group.visible {
+			box.SetChecked(true)
+		}
+	}
+
+	return saver
+}
@@ -3,6 +3,7 @@ package autoconfig
 import (
 	"reflect"
 
+	"github.com/mappu/autoconfig/schema"
 	qt "github.com/mappu/miqt/qt6"
 )
 
@@ -11,7 +12,7 @@ import (
 type Header struct{}
 
 func (Header) Render(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string) SaveFunc {
-	rlabel := qt.NewQLabel3(label)
+	rlabel := qt.NewQLabel3(schema.StripMnemonic(label))
 	area.AddRowWithWidget(rlabel.QWidget) // The widget spans both columns.
 	return func() {}
 }
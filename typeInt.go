@@ -1,24 +1,22 @@
 package autoconfig
 
 import (
-	"math"
 	"reflect"
 
 	"github.com/mappu/autoconfig/qspinbox"
+	"github.com/mappu/autoconfig/schema"
 	qt "github.com/mappu/miqt/qt6"
 )
 
 func handle_int(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string) SaveFunc {
+	min, max := schema.IntBounds(rv.Type(), tag)
+
 	switch rv.Type().Bits() {
-	case 8:
-		return handle_numeric_Int32SpinBox(area, rv, tag, label, math.MinInt8, math.MaxInt8)
-	case 16:
-		return handle_numeric_Int32SpinBox(area, rv, tag, label, math.MinInt16, math.MaxInt16)
-	case 32:
-		return handle_numeric_Int32SpinBox(area, rv, tag, label, math.MinInt32, math.MaxInt32)
+	case 8, 16, 32:
+		return handle_numeric_Int32SpinBox(area, rv, tag, label, int(min), int(max))
 	case 64:
 		// Custom widget
-		return handle_numeric_Int64SpinBox(area, rv, tag, label, math.MinInt64, math.MaxInt64)
+		return handle_numeric_Int64SpinBox(area, rv, tag, label, min, max)
 
 	default:
 		panic("Unknown bit width for integer type")
@@ -26,19 +24,16 @@ func handle_int(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag,
 }
 
 func handle_uint(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string) SaveFunc {
+	min, max := schema.UintBounds(rv.Type(), tag)
+
 	switch rv.Type().Bits() {
-	case 8:
-		return handle_numeric_Uint32SpinBox(area, rv, tag, label, math.MaxUint8)
-	case 16:
-		return handle_numeric_Uint32SpinBox(area, rv, tag, label, math.MaxUint16)
+	case 8, 16:
+		return handle_numeric_Uint32SpinBox(area, rv, tag, label, int(min), int(max))
 
 		// QSpinBox is only capable of (signed) int32 maximum
-	case 32:
+	case 32, 64:
 		// Custom widget
-		return handle_numeric_Uint64SpinBox(area, rv, tag, label, math.MaxUint32)
-	case 64:
-		// Custom widget
-		return handle_numeric_Uint64SpinBox(area, rv, tag, label, math.MaxUint64)
+		return handle_numeric_Uint64SpinBox(area, rv, tag, label, min, max)
 
 	default:
 		panic("Unknown bit width for integer type")
@@ -58,18 +53,22 @@ func handle_numeric_Int32SpinBox(area *qt.QFormLayout, rv *reflect.Value, tag re
 		rint.SetSuffix(suffix)
 	}
 
+	form := activeForm
+	rint.SetReadOnly(form.readOnly)
+	rint.OnEditingFinished(func() { form.edited(editText(label)) })
+
 	addRow(area, label, rint.QWidget)
 	return func() {
 		rv.SetInt(int64(rint.Value()))
 	}
 }
 
-func handle_numeric_Uint32SpinBox(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string, max int) SaveFunc {
+func handle_numeric_Uint32SpinBox(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string, min int, max int) SaveFunc {
 	// WARNING: Only handles int32 bounds, not 0...uint32
 	// Can be used for uint8/16, but, uint32/64 should both use the other specialized implementation
 
 	rint := qt.NewQSpinBox2()
-	rint.SetMinimum(0)
+	rint.SetMinimum(min)
 	rint.SetMaximum(max)
 	rint.SetValue(int(rv.Uint())) // After setting bounds, otherwise it gets clamped
 
@@ -80,6 +79,10 @@ func handle_numeric_Uint32SpinBox(area *qt.QFormLayout, rv *reflect.Value, tag r
 		rint.SetSuffix(suffix)
 	}
 
+	form := activeForm
+	rint.SetReadOnly(form.readOnly)
+	rint.OnEditingFinished(func() { form.edited(editText(label)) })
+
 	addRow(area, label, rint.QWidget)
 	return func() {
 		rv.SetUint(uint64(rint.Value()))
@@ -99,15 +102,19 @@ func handle_numeric_Int64SpinBox(area *qt.QFormLayout, rv *reflect.Value, tag re
 		rint.SetSuffix(suffix)
 	}
 
+	form := activeForm
+	rint.SetReadOnly(form.readOnly)
+	rint.OnEditingFinished(func() { form.edited(editText(label)) })
+
 	addRow(area, label, rint.QWidget)
 	return func() {
 		rv.SetInt(int64(rint.Value()))
 	}
 }
 
-func handle_numeric_Uint64SpinBox(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string, max uint64) SaveFunc {
+func handle_numeric_Uint64SpinBox(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string, min uint64, max uint64) SaveFunc {
 	rint := qspinbox.NewQUint64SpinBox(nil)
-	rint.SetMinimum(0)
+	rint.SetMinimum(min)
 	rint.SetMaximum(max)
 	rint.SetValue(rv.Uint()) // After setting bounds, otherwise it gets clamped
 
@@ -118,6 +125,10 @@ func handle_numeric_Uint64SpinBox(area *qt.QFormLayout, rv *reflect.Value, tag r
 		rint.SetSuffix(suffix)
 	}
 
+	form := activeForm
+	rint.SetReadOnly(form.readOnly)
+	rint.OnEditingFinished(func() { form.edited(editText(label)) })
+
 	addRow(area, label, rint.QWidget)
 	return func() {
 		rv.SetUint(rint.Value())
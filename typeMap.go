@@ -19,8 +19,13 @@ func (mapKvPair) Render(area *qt.QFormLayout, rv *reflect.Value, tag reflect.Str
 	kField := rv.Field(0).Interface().(reflect.Value)
 	vField := rv.Field(1).Interface().(reflect.Value)
 
-	kSaver := handle_any(area, &kField, tag, "Key")
-	vSaver := handle_any(area, &vField, tag, "Value")
+	var kSaver, vSaver SaveFunc
+	activeForm.fieldScope(area, "Key", func() {
+		kSaver = handle_any(area, &kField, tag, tr("Key"))
+	})
+	activeForm.fieldScope(area, "Value", func() {
+		vSaver = handle_any(area, &vField, tag, tr("Value"))
+	})
 
 	return func() {
 		kSaver()
@@ -33,10 +38,12 @@ func handle_map(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag,
 	// If there is a struct tag applied to the map, it will be not used here
 	// at all, but it will be propagated into the renderer for both key+value.
 
+	form := activeForm
+
 	itemList := qt.NewQTreeWidget2()
 	itemList.SetHeaderHidden(false)
 	itemList.SetColumnCount(2)
-	itemList.SetHeaderLabels([]string{"Key", "Value"})
+	itemList.SetHeaderLabels([]string{tr("Key"), tr("Value")})
 	itemList.SetUniformRowHeights(true)
 	itemList.SetRootIsDecorated(false)
 	itemList.SetSelectionMode(qt.QAbstractItemView__ContiguousSelection)
@@ -67,7 +74,7 @@ func handle_map(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag,
 			// (*T) String() if vField has type T
 			// Although it works if Stringer is implemented on the value receiver
 
-			listItem := qt.NewQTreeWidgetItem2([]string{formatValue(&kField), formatValue(&vField)})
+			listItem := qt.NewQTreeWidgetItem2([]string{formatValue(&kField, ""), formatValue(&vField, tag)})
 			itemList.AddTopLevelItem(listItem)
 		}
 	}
@@ -76,8 +83,10 @@ func handle_map(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag,
 	// Adding
 
 	addButton := qt.NewQToolButton2()
+	addButton.SetObjectName(*qt.NewQAnyStringView3("add")) // For finding it in tests
 	setIcon(addButton.QAbstractButton, "list-add", "+", "Add...")
 	addButton.SetAutoRaise(true)
+	addButton.SetEnabled(!form.readOnly)
 	addButton.OnClicked(func() {
 
 		newKey := reflect.New(rv.Type().Key() /* T */) // pointer-to-T, not a T
@@ -105,12 +114,14 @@ func handle_map(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag,
 
 			// refresh list
 			refreshListContent()
-		})
+			form.edited(undoText("Add", label))
+		}, form.nested())
 	})
 
 	// Editing (Slice or Array)
 
 	editButton := qt.NewQToolButton2()
+	editButton.SetObjectName(*qt.NewQAnyStringView3("edit"))
 
 	editIndex := func(idx int) {
 
@@ -133,6 +144,10 @@ func handle_map(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag,
 		pairRv := reflect.ValueOf(&pair).Elem()
 
 		openDialogFor(&pairRv, editButton.QWidget, tag, label, func() {
+			if form.readOnly {
+				return
+			}
+
 			// Move our copied values back into the map
 
 			rv.SetMapIndex(curKey, reflect.Value{})
@@ -141,7 +156,8 @@ func handle_map(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag,
 
 			// refresh list
 			refreshListContent()
-		})
+			form.edited(undoText("Edit", label))
+		}, form.nested())
 	}
 	itemList.OnDoubleClicked(func(idx *qt.QModelIndex) {
 		if idx == nil {
@@ -150,7 +166,12 @@ func handle_map(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag,
 		editIndex(idx.Row())
 	})
 
-	setIcon(editButton.QAbstractButton, "document-edit-symbolic", "\u270e" /* pencil emoji */, "Edit...")
+	if form.readOnly {
+		// Nested dialogs can still be opened, to view the item
+		setIcon(editButton.QAbstractButton, "document-open", "\u2026" /* &hellip; */, "View...")
+	} else {
+		setIcon(editButton.QAbstractButton, "document-edit-symbolic", "\u270e" /* pencil emoji */, "Edit...")
+	}
 	editButton.SetAutoRaise(true)
 	editButton.OnClicked(func() {
 		curIdx := itemList.CurrentIndex()
@@ -163,6 +184,7 @@ func handle_map(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag,
 	// Deleting
 
 	delButton := qt.NewQToolButton2()
+	delButton.SetObjectName(*qt.NewQAnyStringView3("remove"))
 	setIcon(delButton.QAbstractButton, "edit-delete-symbolic", "\u00d7" /* &times; */, "Remove")
 	delButton.SetAutoRaise(true)
 
@@ -189,12 +211,13 @@ func handle_map(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag,
 
 		// re-render list
 		refreshListContent()
+		form.edited(undoText("Remove", label))
 	})
 
 	refreshButtonsEnabled := func() {
 		selCt := len(itemList.SelectedItems())
 		editButton.SetEnabled(selCt == 1)
-		delButton.SetEnabled(selCt > 0)
+		delButton.SetEnabled(selCt > 0 && !form.readOnly)
 	}
 	refreshButtonsEnabled()
 	itemList.OnSelectionChanged(func(super func(*qt.QItemSelection, *qt.QItemSelection), selected, deselected *qt.QItemSelection) {
@@ -13,6 +13,7 @@ func (MultiLineString) Render(area *qt.QFormLayout, rv *reflect.Value, tag refle
 	rline := qt.NewQTextEdit2()
 	rline.SetPlainText(rv.String())
 	rline.SetAcceptRichText(false)
+	rline.SetReadOnly(activeForm.readOnly)
 	addRow(area, label, rline.QWidget)
 	return func() {
 		rv.SetString(rline.ToPlainText())
@@ -1,9 +1,11 @@
 package autoconfig
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
 
+	"github.com/mappu/autoconfig/schema"
 	qt "github.com/mappu/miqt/qt6"
 )
 
@@ -18,7 +20,9 @@ import (
 // a Qt embedded resource.
 //
 // When saving, only the selected struct member will be populated; all other
-// values will be set to nil.
+// values will be set to nil. If nothing was selected, and the user doesn't
+// choose an option or change the values of the one shown, the struct is left
+// unchanged.
 type OneOf string
 
 // yicon_from_tag constructs a *QIcon from the `yicon` field in the struct tag.
@@ -47,83 +51,158 @@ func yicon_from_tag(tag reflect.StructTag) *qt.QIcon {
 func handle_struct_as_OneOf(area *qt.QFormLayout, rv *reflect.Value, _ reflect.StructTag, _ string) SaveFunc {
 
 	obj := rv.Type()
+	form := activeForm
 
 	initialValue := rv.Field(0).String()
 	var initialIndex int = 0
 
 	picker := qt.NewQComboBox2()
 
-	nf := obj.NumField()
-	for i := 1; i < nf; i++ { // skip ourselves, we were element 0
-		ff := obj.Field(i)
-
-		picker.AddItem(struct_field_label(ff))
-		if initialValue == ff.Name {
-			initialIndex = i - 1
+	// The options in display order, without the OneOf itself
+	options := schema.FormFields(obj, form.layout()...)
+	for i, option := range options {
+		picker.AddItem3(option.Label, qt.NewQVariant11(option.Name)) // The name is for tests
+		if initialValue == option.Name {
+			initialIndex = i
 		}
 
-		if icon := yicon_from_tag(ff.Tag); icon != nil {
-			picker.SetItemIcon(i-1, icon)
+		if icon := yicon_from_tag(option.Tag); icon != nil {
+			picker.SetItemIcon(i, icon)
 		}
 	}
 
 	picker.SetCurrentIndex(initialIndex)
-	area.AddRowWithWidget(picker.QWidget)
 
 	stack := qt.NewQStackedLayout2()
 
 	var allSavers []func()
+	var allValues []reflect.Value     // Pointers that each frame is editing
+	var initialFields []reflect.Value // Copies of each option's field before editing
+	var initialPages []reflect.Value  // Copies of the values that each frame starts with
+	var pageNodes []*filterNode
+
+	group := form.filterScope(area, "", func() {
+		area.AddRowWithWidget(picker.QWidget)
+
+		picker.SetEnabled(!form.readOnly)
+		if envName, ok := form.envOverride(rv.Field(0)); ok {
+			picker.SetEnabled(false)
+			picker.SetToolTip(fmt.Sprintf(tr("This selection is set by the %s environment variable, and can't be changed here."), envName))
+		}
 
-	for i := 1; i < nf; i++ { // skip ourselves, we were element 0
-		ff := rv.Field(i)
-
-		frameWidget := qt.NewQWidget(area.ParentWidget())
+		for _, option := range options {
+			ff := rv.Field(option.Index)
 
-		frame := qt.NewQFormLayout(frameWidget)
-		//frameWidget.SetLayout(frame.QLayout)
+			frameWidget := qt.NewQWidget(area.ParentWidget())
 
-		if ff.Kind() != reflect.Pointer {
-			// Weird, everything else in here should be a pointer
-			panic("OneOf: expected all other struct members to be pointer types")
-		}
+			frame := qt.NewQFormLayout(frameWidget)
+			//frameWidget.SetLayout(frame.QLayout)
 
-		// If the value is nil, we have to new it, to have something to work with
-		if ff.IsNil() {
-			ff.Set(reflect.New(ff.Type().Elem()))
-
-			if defaulter, ok := ff.Interface().(Resetter); ok {
-				defaulter.Reset()
+			if ff.Kind() != reflect.Pointer {
+				// Weird, everything else in here should be a pointer
+				panic("OneOf: expected all other struct members to be pointer types")
 			}
-		}
 
-		child := ff.Elem()
+			// If the value is nil, we have to new it, to have something to work with
+			ptr := ff
+			if ff.IsNil() {
+				ptr = reflect.New(ff.Type().Elem())
 
-		// Don't pass in the struct's label here, we already showed it for the tab title
-		saver := makeConfigAreaFor(&child, frame, reflect.StructTag(""), "")
+				if defaulter, ok := ptr.Interface().(Resetter); ok {
+					defaulter.Reset()
+				}
 
-		stack.AddWidget(frameWidget)
+				// Only stored in the struct when saving, so that a OneOf
+				// without a selection is left alone
+			}
 
-		allSavers = append(allSavers, saver)
-	}
+			child := ptr.Elem()
+			allValues = append(allValues, ptr)
+			initialField := reflect.New(ff.Type()).Elem()
+			initialField.Set(ff)
+			initialFields = append(initialFields, initialField)
+			initialPages = append(initialPages, schema.Clone(child))
+
+			var saver SaveFunc
+			var pageNode *filterNode
+			form.fieldScope(frame, option.Name, func() {
+				pageNode = form.filterScope(frame, option.Label, func() {
+					// Don't pass in the struct's label here, we already showed it for the tab title
+					saver = makeConfigAreaFor(&child, frame, reflect.StructTag(""), "")
+				})
+			})
+			pageNodes = append(pageNodes, pageNode)
+
+			stack.AddWidget(frameWidget)
+
+			allSavers = append(allSavers, saver)
+		}
 
-	area.AddRowWithLayout(stack.QLayout)
+		area.AddRowWithLayout(stack.QLayout)
+	})
 
 	picker.OnCurrentIndexChanged(func(idx int) {
 		stack.SetCurrentIndex(idx)
 	})
 	stack.SetCurrentIndex(initialIndex)
 
+	// When searching, show a page with matches, and go back afterwards
+	filterIndex := -1 // The user's selection, if the filter changed it
+	group.reveal = func(query string) {
+		if query == "" {
+			if filterIndex != -1 {
+				picker.SetCurrentIndex(filterIndex)
+				filterIndex = -1
+			}
+			return
+		}
+
+		cur := picker.CurrentIndex()
+		if pageNodes[cur].visible {
+			return
+		}
+		if idx := firstVisible(pageNodes); idx != -1 {
+			if filterIndex == -1 {
+				filterIndex = cur
+			}
+			picker.SetCurrentIndex(idx)
+		}
+	}
+
+	chosen := false // The user chose an option
+	picker.OnActivated(func(int) {
+		chosen = true
+		filterIndex = -1 // Keep the user's new selection
+		form.edited(undoText("Change", schema.FieldLabel(obj, obj.Field(0))))
+	})
+
 	return func() {
 
 		// Commit current frame
+		// The frame's value may have been cleared by a previous save
 		cidx := picker.CurrentIndex()
+		if cidx < 0 {
+			return // No options
+		}
 		allSavers[cidx]()
 
+		if initialValue == "" && !chosen && len(schema.Diff(initialPages[cidx], allValues[cidx].Elem())) == 0 {
+			// Nothing was selected or changed, so keep it that way
+			rv.Field(0).SetString("")
+			for i, option := range options {
+				rv.Field(option.Index).Set(initialFields[i])
+			}
+			return
+		}
+
+		selected := options[cidx].Index
+		rv.Field(selected).Set(allValues[cidx])
+
 		// Save current selection into the picker value
-		rv.Field(0).SetString(rv.Type().Field(cidx + 1).Name)
+		rv.Field(0).SetString(options[cidx].Name)
 
-		for i := 1; i < nf; i++ {
-			if i == (cidx + 1) {
+		for i := 1; i < obj.NumField(); i++ {
+			if i == selected {
 				continue // keeping this one
 			}
 
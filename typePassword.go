@@ -13,6 +13,9 @@ func (Password) Render(area *qt.QFormLayout, rv *reflect.Value, tag reflect.Stru
 	rline := qt.NewQLineEdit2()
 	rline.SetEchoMode(qt.QLineEdit__Password)
 	rline.SetText(rv.String())
+	form := activeForm
+	rline.SetReadOnly(form.readOnly)
+	rline.OnEditingFinished(func() { form.edited(editText(label)) })
 	addRow(area, label, rline.QWidget)
 	return func() {
 		rv.SetString(rline.Text())
@@ -16,12 +16,21 @@ func handle_pointer(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructT
 	hbox.AddWidget(statusField.QWidget)
 
 	refreshLabel := func() {
-		statusField.SetText(formatValue(rv))
+		statusField.SetText(formatValue(rv, tag))
 	}
 	refreshLabel()
 
+	form := activeForm
+
 	configBtn := qt.NewQToolButton2()
-	setIcon(configBtn.QAbstractButton, "edit-symbolic", "\u270e" /* pencil emoji */, "Edit...")
+	configBtn.SetObjectName(*qt.NewQAnyStringView3("edit")) // For finding it in tests
+	if form.readOnly {
+		// Nested dialogs can still be opened, to view the value
+		setIcon(configBtn.QAbstractButton, "document-open", "\u2026" /* &hellip; */, "View...")
+		configBtn.SetEnabled(!rv.IsNil())
+	} else {
+		setIcon(configBtn.QAbstractButton, "edit-symbolic", "\u270e" /* pencil emoji */, "Edit...")
+	}
 	configBtn.OnClicked(func() {
 
 		// Allocate our rv to be something if it's nothing
@@ -46,7 +55,8 @@ func handle_pointer(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructT
 		openDialogFor(&child, configBtn.QWidget, tag, label, func() {
 			// nothing to do
 			refreshLabel()
-		})
+			form.edited(editText(label))
+		}, form.nested())
 	})
 	hbox.AddWidget(configBtn.QWidget)
 
@@ -54,7 +64,9 @@ func handle_pointer(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructT
 
 	if _, ok := rv.Interface().(Resetter); ok {
 		resetBtn := qt.NewQToolButton2()
+		resetBtn.SetObjectName(*qt.NewQAnyStringView3("reset"))
 		setIcon(resetBtn.QAbstractButton, "view-refresh", "\u27F3", "Reset to defaults")
+		resetBtn.SetEnabled(!form.readOnly)
 		resetBtn.OnClicked(func() {
 			if rv.IsNil() {
 				rv.Set(reflect.New(rv.Type().Elem()))
@@ -68,6 +80,7 @@ func handle_pointer(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructT
 			}
 
 			refreshLabel()
+			form.edited(undoText("Reset", label))
 		})
 		hbox.AddWidget(resetBtn.QWidget)
 	}
@@ -75,12 +88,15 @@ func handle_pointer(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructT
 	// Clear button
 
 	clearBtn := qt.NewQToolButton2()
+	clearBtn.SetObjectName(*qt.NewQAnyStringView3("clear"))
 	setIcon(clearBtn.QAbstractButton, "edit-clear", "\u00d7" /* &times; */, "Clear")
+	clearBtn.SetEnabled(!form.readOnly)
 	clearBtn.OnClicked(func() {
 		if !rv.IsNil() {
 			rv.Set(reflect.Zero(rv.Type()))
 		}
 		refreshLabel()
+		form.edited(undoText("Clear", label))
 	})
 	hbox.AddWidget(clearBtn.QWidget)
 
@@ -88,6 +104,7 @@ func handle_pointer(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructT
 
 	hboxWidget := qt.NewQWidget(area.ParentWidget())
 	hboxWidget.SetLayout(hbox.QLayout)
+	hboxWidget.SetFocusProxy(configBtn.QWidget)
 	addRow(area, label, hboxWidget)
 
 	return func() {
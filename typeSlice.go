@@ -15,6 +15,8 @@ func handle_slice_or_array(area *qt.QFormLayout, rv *reflect.Value, tag reflect.
 
 	buttons := make([]*qt.QToolButton, 0, 3)
 
+	form := activeForm
+
 	itemList := qt.NewQTreeWidget2()
 	itemList.SetHeaderHidden(true)
 	itemList.SetUniformRowHeights(true)
@@ -26,7 +28,7 @@ func handle_slice_or_array(area *qt.QFormLayout, rv *reflect.Value, tag reflect.
 		sliceItemsCt := rv.Len()
 		for i := 0; i < sliceItemsCt; i++ {
 			sliceElem := rv.Index(i)
-			listItem := qt.NewQTreeWidgetItem2([]string{formatValue(&sliceElem)})
+			listItem := qt.NewQTreeWidgetItem2([]string{formatValue(&sliceElem, tag)})
 			itemList.AddTopLevelItem(listItem)
 		}
 	}
@@ -37,8 +39,10 @@ func handle_slice_or_array(area *qt.QFormLayout, rv *reflect.Value, tag reflect.
 	if rv.Kind() == reflect.Slice {
 
 		addButton := qt.NewQToolButton2()
+		addButton.SetObjectName(*qt.NewQAnyStringView3("add")) // For finding it in tests
 		setIcon(addButton.QAbstractButton, "list-add", "+", "Add...")
 		addButton.SetAutoRaise(true)
+		addButton.SetEnabled(!form.readOnly)
 		addButton.OnClicked(func() {
 
 			newElem := reflect.New(rv.Type().Elem() /* T */) // pointer-to-T, not a T
@@ -54,7 +58,8 @@ func handle_slice_or_array(area *qt.QFormLayout, rv *reflect.Value, tag reflect.
 
 				// refresh list
 				refreshListContent()
-			})
+				form.edited(undoText("Add", label))
+			}, form.nested())
 		})
 		buttons = append(buttons, addButton)
 
@@ -63,6 +68,7 @@ func handle_slice_or_array(area *qt.QFormLayout, rv *reflect.Value, tag reflect.
 	// Editing (Slice or Array)
 
 	editButton := qt.NewQToolButton2()
+	editButton.SetObjectName(*qt.NewQAnyStringView3("edit"))
 
 	editIndex := func(idx int) {
 		curVal := rv.Index(idx)
@@ -72,7 +78,8 @@ func handle_slice_or_array(area *qt.QFormLayout, rv *reflect.Value, tag reflect.
 
 			// refresh list
 			refreshListContent()
-		})
+			form.edited(undoText("Edit", label))
+		}, form.nested())
 	}
 	itemList.OnDoubleClicked(func(idx *qt.QModelIndex) {
 		if idx == nil {
@@ -81,7 +88,12 @@ func handle_slice_or_array(area *qt.QFormLayout, rv *reflect.Value, tag reflect.
 		editIndex(idx.Row())
 	})
 
-	setIcon(editButton.QAbstractButton, "document-edit-symbolic", "\u270e" /* pencil emoji */, "Edit...")
+	if form.readOnly {
+		// Nested dialogs can still be opened, to view the item
+		setIcon(editButton.QAbstractButton, "document-open", "\u2026" /* &hellip; */, "View...")
+	} else {
+		setIcon(editButton.QAbstractButton, "document-edit-symbolic", "\u270e" /* pencil emoji */, "Edit...")
+	}
 	editButton.SetAutoRaise(true)
 	editButton.OnClicked(func() {
 		curIdx := itemList.CurrentIndex()
@@ -98,6 +110,7 @@ func handle_slice_or_array(area *qt.QFormLayout, rv *reflect.Value, tag reflect.
 	var delButton *qt.QToolButton = nil
 	if rv.Kind() == reflect.Slice {
 		delButton = qt.NewQToolButton2()
+		delButton.SetObjectName(*qt.NewQAnyStringView3("remove"))
 		setIcon(delButton.QAbstractButton, "edit-delete-symbolic", "\u00d7" /* &times; */, "Remove")
 		delButton.SetAutoRaise(true)
 
@@ -123,6 +136,7 @@ func handle_slice_or_array(area *qt.QFormLayout, rv *reflect.Value, tag reflect.
 
 			// re-render list
 			refreshListContent()
+			form.edited(undoText("Remove", label))
 		})
 
 		buttons = append(buttons, delButton)
@@ -133,7 +147,7 @@ func handle_slice_or_array(area *qt.QFormLayout, rv *reflect.Value, tag reflect.
 		selCt := len(itemList.SelectedItems())
 		editButton.SetEnabled(selCt == 1)
 		if delButton != nil { // slice only
-			delButton.SetEnabled(selCt > 0)
+			delButton.SetEnabled(selCt > 0 && !form.readOnly)
 		}
 	}
 	refreshButtonsEnabled()
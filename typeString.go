@@ -9,6 +9,10 @@ import (
 func handle_string(area *qt.QFormLayout, rv *reflect.Value, tag reflect.StructTag, label string) SaveFunc {
 	rline := qt.NewQLineEdit2()
 	rline.SetText(rv.String())
+	form := activeForm
+	rline.SetReadOnly(form.readOnly)
+	rline.OnEditingFinished(func() { form.edited(editText(label)) })
+	form.addCompleter(rline, rv, tag)
 	addRow(area, label, rline.QWidget)
 	return func() {
 		rv.SetString(rline.Text())
@@ -2,74 +2,132 @@ package autoconfig
 
 import (
 	"reflect"
-	"strings"
 
+	"github.com/mappu/autoconfig/schema"
 	qt "github.com/mappu/miqt/qt6"
 )
 
-func struct_field_label(ff reflect.StructField) string {
-	if useLabel, ok := ff.Tag.Lookup("ylabel"); ok { // Explicit name
-		return useLabel
-	}
-
-	// Automatic name: field value with _ as spaces
-	return formatLabel(ff.Name)
-}
-
 func handle_struct(area *qt.QFormLayout, rv *reflect.Value, self_tag reflect.StructTag, self_label string) SaveFunc {
 
 	// ignore tag and label
 
 	obj := rv.Type()
 
+	// Hooks for special struct types
+	switch schema.Classify(obj, self_tag) {
+	case schema.KindOneOf:
+		return handle_struct_as_OneOf(area, rv, self_tag, self_label)
+	case schema.KindTabGroup:
+		return handle_struct_as_TabGroup(area, rv, self_tag, self_label)
+	case schema.KindGroupBox:
+		return handle_struct_as_GroupBox(area, rv, self_tag, self_label)
+	}
+
+	return handle_struct_fields(area, rv, schema.FormFields(obj, activeForm.layout()...))
+}
+
+// handle_struct_fields adds each of the struct's fields to the area. Any
+// consecutive fields with the same `ygroup` tag are placed in a group box.
+func handle_struct_fields(area *qt.QFormLayout, rv *reflect.Value, fields []*schema.Field) SaveFunc {
+
 	var onApply []SaveFunc
 
-	nf := obj.NumField()
-	for i := 0; i < nf; i++ {
-		ff := obj.Field(i)
-
-		// Hooks for special struct types
-		if i == 0 {
-			switch {
-			case ff.Type == reflect.TypeOf(OneOf("")):
-				return handle_struct_as_OneOf(area, rv, self_tag, self_label)
-			case ff.Type == reflect.TypeOf(TabGroup{}):
-				return handle_struct_as_TabGroup(area, rv, self_tag, self_label)
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+
+		if field.Group != "" {
+			end := i + 1
+			for end < len(fields) && fields[end].Group == field.Group {
+				end++
 			}
-		}
 
-		// Don't show private fields
-		if !ff.IsExported() {
+			members := fields[i:end]
+			onApply = append(onApply, addGroupBox(area, field.Group, field.Tag, func(inner *qt.QFormLayout) SaveFunc {
+				var savers []SaveFunc
+				for _, member := range members {
+					savers = append(savers, handle_struct_field(inner, rv, member))
+				}
+				return func() {
+					for _, saver := range savers {
+						saver()
+					}
+				}
+			}))
+
+			i = end - 1
 			continue
 		}
 
-		fieldValue := rv.Field(i)
+		onApply = append(onApply, handle_struct_field(area, rv, field))
+	}
 
-		// Heuristic: if a string field name is SomethingDir, lift to ExistingDirectory
-		var singleFieldSaver SaveFunc
+	// Save all
+	return func() {
+		for _, fn := range onApply {
+			fn()
+		}
+	}
+}
 
-		if ff.Type == reflect.TypeOf("") && strings.HasSuffix(ff.Name, `Dir`) {
-			tmp := ExistingDirectory("")
-			singleFieldSaver = tmp.Render(area, &fieldValue, ff.Tag, struct_field_label(ff))
+func handle_struct_field(area *qt.QFormLayout, rv *reflect.Value, field *schema.Field) SaveFunc {
+	fieldValue := rv.FieldByIndex(field.IndexPath)
 
-		} else if ff.Type == reflect.TypeOf("") && (strings.HasSuffix(ff.Name, `Pass`) || strings.HasSuffix(ff.Name, `Password`)) {
-			tmp := Password("")
-			singleFieldSaver = tmp.Render(area, &fieldValue, ff.Tag, struct_field_label(ff))
+	var singleFieldSaver SaveFunc
+	var node *filterNode
 
-		} else {
-			singleFieldSaver = handle_any(area, &fieldValue, ff.Tag, struct_field_label(ff))
-		}
+	name := field.Name
+	if field.Embedded {
+		name = "" // Embedded fields don't add a path segment
+	}
 
-		onApply = append(onApply, func() {
-			singleFieldSaver()
+	// The struct that has the field, which may be promoted from an embedded struct
+	prevOwner := activeForm.owner
+	activeForm.owner = rv.FieldByIndex(field.IndexPath[:len(field.IndexPath)-1])
+	defer func() { activeForm.owner = prevOwner }()
+
+	activeForm.fieldScope(area, name, func() {
+		// Record the field's rows, so that the search filter can hide them
+		node = activeForm.filterScope(area, field.Label+"\n"+field.Name+"\n"+field.Help, func() {
+			if _, ok := field.Tag.Lookup("yreadonly"); ok && !activeForm.readOnly {
+				singleFieldSaver = activeForm.buildReadOnly(func() SaveFunc {
+					return handle_struct_field_widget(area, &fieldValue, field)
+				})
+			} else {
+				singleFieldSaver = handle_struct_field_widget(area, &fieldValue, field)
+			}
 		})
-
+	})
+
+	// Nested structs describe their own fields instead
+	switch field.Kind {
+	case schema.KindStruct, schema.KindTabGroup, schema.KindGroupBox, schema.KindOneOf:
+	default:
+		if field.Help != "" {
+			setAccessibleDescription(area, node.firstRow, node.endRow, field.Help)
+		}
+		activeForm.addResetAction(area, node.firstRow, node.endRow, rv, field, fieldValue)
 	}
 
-	// Save all
 	return func() {
-		for _, fn := range onApply {
-			fn()
-		}
+		singleFieldSaver()
 	}
 }
+
+// handle_struct_field_widget adds the widget for a single struct field.
+func handle_struct_field_widget(area *qt.QFormLayout, fieldValue *reflect.Value, field *schema.Field) SaveFunc {
+	if envName, ok := activeForm.envOverride(*fieldValue); ok {
+		return handle_env_override(area, fieldValue, field.Tag, field.Mnemonic, envName, field.Secret)
+
+	} else if field.Kind == schema.KindExistingDirectory && field.Type == reflect.TypeOf("") {
+		// Heuristic: if a string field name is SomethingDir, lift to ExistingDirectory
+		tmp := ExistingDirectory("")
+		return tmp.Render(area, fieldValue, field.Tag, field.Mnemonic)
+
+	} else if field.Kind == schema.KindPassword && field.Type == reflect.TypeOf("") {
+		// Heuristic: if a string field name is SomethingPassword, lift to Password
+		tmp := Password("")
+		return tmp.Render(area, fieldValue, field.Tag, field.Mnemonic)
+	}
+
+	return handle_any(area, fieldValue, field.Tag, field.Mnemonic)
+}
@@ -3,6 +3,7 @@ package autoconfig
 import (
 	"reflect"
 
+	"github.com/mappu/autoconfig/schema"
 	qt "github.com/mappu/miqt/qt6"
 )
 
@@ -19,39 +20,64 @@ type TabGroup struct{}
 func handle_struct_as_TabGroup(area *qt.QFormLayout, rv *reflect.Value, _ reflect.StructTag, _ string) SaveFunc {
 
 	obj := rv.Type()
-	nf := obj.NumField()
 
 	tabArea := qt.NewQTabWidget(area.ParentWidget())
 
 	var allSavers []func()
+	var tabNodes []*filterNode
 
-	for i := 1; i < nf; i++ { // skip ourselves, we were element 0
-		ff := obj.Field(i)  // Typeinfo only, not value
-		valf := rv.Field(i) // Value
+	form := activeForm
+	group := form.filterScope(area, "", func() {
+		// The tabs in display order, without the TabGroup itself
+		for i, tab := range schema.FormFields(obj, form.layout()...) {
+			valf := rv.FieldByIndex(tab.IndexPath)
 
-		// Handle icon
+			// Handle icon
 
-		useIcon := yicon_from_tag(ff.Tag)
+			useIcon := yicon_from_tag(tab.Tag)
 
-		// Create tab frame
+			// Create tab frame
 
-		frameWidget := qt.NewQWidget(area.ParentWidget())
+			frameWidget := qt.NewQWidget(area.ParentWidget())
 
-		frame := qt.NewQFormLayout(frameWidget)
+			frame := qt.NewQFormLayout(frameWidget)
 
-		// Don't pass in the struct's label here, we already showed it for the tab title
-		saver := makeConfigAreaFor(&valf, frame, reflect.StructTag(""), "")
+			var saver SaveFunc
+			var tabNode *filterNode
+			form.fieldScope(frame, tab.Name, func() {
+				tabNode = form.filterScope(frame, tab.Label, func() {
+					// Don't pass in the struct's label here, we already showed it for the tab title
+					saver = makeConfigAreaFor(&valf, frame, reflect.StructTag(""), "")
+				})
+			})
 
-		if useIcon != nil {
-			tabArea.AddTab2(frameWidget, useIcon, struct_field_label(ff))
-		} else {
-			tabArea.AddTab(frameWidget, struct_field_label(ff))
+			tabIndex := i
+			tabNode.setVisible = func(visible bool) {
+				tabArea.SetTabVisible(tabIndex, visible)
+			}
+			tabNodes = append(tabNodes, tabNode)
+
+			if useIcon != nil {
+				tabArea.AddTab2(frameWidget, useIcon, tab.Mnemonic)
+			} else {
+				tabArea.AddTab(frameWidget, tab.Mnemonic)
+			}
+
+			allSavers = append(allSavers, saver)
 		}
 
-		allSavers = append(allSavers, saver)
-	}
+		area.AddRowWithWidget(tabArea.QWidget)
+	})
 
-	area.AddRowWithWidget(tabArea.QWidget)
+	// When searching, switch away from a tab without any matches
+	group.reveal = func(string) {
+		if cur := tabArea.CurrentIndex(); cur >= 0 && tabNodes[cur].visible {
+			return
+		}
+		if idx := firstVisible(tabNodes); idx != -1 {
+			tabArea.SetCurrentIndex(idx)
+		}
+	}
 
 	return func() {
 		// Run all savers
@@ -16,6 +16,9 @@ func handle_stdlibTimeTime(area *qt.QFormLayout, rv *reflect.Value, tag reflect.
 	dt.SetSecsSinceEpoch(ptrT.Unix())
 
 	rpicker.SetDateTime(dt)
+	form := activeForm
+	rpicker.SetReadOnly(form.readOnly)
+	rpicker.OnEditingFinished(func() { form.edited(editText(label)) })
 
 	addRow(area, label, rpicker.QWidget)
 
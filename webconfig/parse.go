@@ -0,0 +1,398 @@
+package webconfig
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mappu/autoconfig/schema"
+)
+
+// Form field names are based on the field path, e.g. "Network.ListenPort".
+// Slice elements and map entries use their index, e.g. "Peers.0".
+// Extra inputs for a field use a suffix that can't be a Go identifier.
+const (
+	actionName   = "~action" // Submit button for add, remove, etc
+	suffixSet    = "~set"    // Pointer is not nil
+	suffixLen    = "~len"    // Number of slice elements or map entries
+	suffixKey    = "~key"    // Map entry key
+	suffixUnit   = "~unit"   // Factor unit index
+	suffixOption = "~option" // OneOf selection
+)
+
+// Actions, followed by a colon and the field name
+const (
+	actionAdd    = "add"
+	actionRemove = "remove"
+	actionSet    = "set"
+	actionClear  = "clear"
+	actionReset  = "reset"
+)
+
+func join(name, child string) string {
+	if name == "" {
+		return child
+	}
+	return name + "." + child
+}
+
+func actionFor(action, name string) string {
+	return action + ":" + name
+}
+
+// sortedKeys gets the keys of a map in a stable order.
+func sortedKeys(rv reflect.Value) []reflect.Value {
+	keys := rv.MapKeys()
+	sort.SliceStable(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	return keys
+}
+
+// parser applies submitted form values to a copy of the struct.
+//
+// Fields that are missing from the form are left unchanged, so a client may
+// submit only some fields. Pointers, slices and maps are always copied before
+// they are changed, so the original struct is never modified.
+type parser struct {
+	form   url.Values
+	action string
+	errs   map[string]string
+	owner  reflect.Value // The struct that has the field being parsed, for EnumStringOptionsFor
+}
+
+func (p *parser) value(name string) (string, bool) {
+	vs, ok := p.form[name]
+	if !ok || len(vs) == 0 {
+		return "", false
+	}
+	// Use the last value, so that a checkbox can follow a hidden default
+	return strings.ReplaceAll(vs[len(vs)-1], "\r\n", "\n"), true
+}
+
+func (p *parser) fail(name string, err error) {
+	if _, ok := p.errs[name]; !ok {
+		p.errs[name] = err.Error()
+	}
+}
+
+func (p *parser) parseField(f *schema.Field, rv reflect.Value, name string) {
+	switch f.Kind {
+	case schema.KindFixed, schema.KindHeader:
+		// Nothing to parse
+
+	case schema.KindStruct, schema.KindTabGroup, schema.KindGroupBox:
+		for _, child := range f.Expand().FormFields() {
+			childName := name
+			if !child.Embedded {
+				childName = join(name, child.Name)
+			}
+			p.parseChild(rv, child, childName)
+		}
+
+	case schema.KindOneOf:
+		p.parseOneOf(f.Expand(), rv, name)
+
+	case schema.KindPointer:
+		p.parsePointer(f, rv, name)
+
+	case schema.KindSlice, schema.KindArray:
+		p.parseList(f, rv, name)
+
+	case schema.KindMap:
+		p.parseMap(f, rv, name)
+
+	case schema.KindFactor, schema.KindDuration:
+		p.parseFactor(f, rv, name)
+
+	case schema.KindPassword:
+		if s, ok := p.value(name); ok && s != "" {
+			p.parseText(f, rv, name)
+		}
+
+	case schema.KindCustom:
+		if !schema.IsText(f.Type) {
+			return // Can't be edited here
+		}
+		p.parseText(f, rv, name)
+
+	default:
+		p.parseText(f, rv, name)
+	}
+}
+
+// parseChild parses a field of the struct, which is its owner.
+func (p *parser) parseChild(rv reflect.Value, f *schema.Field, name string) {
+	prevOwner := p.owner
+	p.owner = rv.FieldByIndex(f.IndexPath[:len(f.IndexPath)-1])
+	defer func() { p.owner = prevOwner }()
+
+	p.parseField(f, rv.FieldByIndex(f.IndexPath), name)
+}
+
+func (p *parser) parseText(f *schema.Field, rv reflect.Value, name string) {
+	s, ok := p.value(name)
+	if !ok {
+		return
+	}
+
+	err := schema.ParseTextFor(p.owner, f.Name, rv, f.Tag, s)
+	if err != nil {
+		p.fail(name, err)
+	}
+}
+
+func (p *parser) parseFactor(f *schema.Field, rv reflect.Value, name string) {
+	s, ok := p.value(name)
+	if !ok {
+		return
+	}
+
+	unit, ok := p.value(name + suffixUnit)
+	if !ok {
+		// Allow a single value with the unit as text, e.g. "10MiB"
+		p.parseText(f, rv, name)
+		return
+	}
+
+	idx, err := strconv.Atoi(unit)
+	if err != nil || idx < 0 || idx >= len(f.Factors) {
+		p.fail(name, fmt.Errorf("invalid unit %q", unit))
+		return
+	}
+
+	num, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		p.fail(name, fmt.Errorf("invalid number %q", s))
+		return
+	}
+
+	val := num * f.Factors[idx].Divisor
+	if val/f.Factors[idx].Divisor != num {
+		p.fail(name, errors.New("value is too large"))
+		return
+	}
+
+	rv.SetInt(val)
+}
+
+func (p *parser) parseOneOf(f *schema.Field, rv reflect.Value, name string) {
+	if s, ok := p.value(name + suffixOption); ok {
+		found := false
+		for _, option := range f.FormFields() {
+			if option.Name == s {
+				schema.SelectOneOf(rv, option.Index)
+				found = true
+				break
+			}
+		}
+		if !found {
+			p.fail(name+suffixOption, fmt.Errorf("invalid option %q", s))
+			return
+		}
+	}
+
+	// Only the selected option is parsed
+	current := rv.Field(0).String()
+	for _, option := range f.FormFields() {
+		if option.Name != current {
+			continue
+		}
+
+		ptr := rv.Field(option.Index)
+		if option.Kind != schema.KindPointer {
+			p.parseField(option, ptr, join(name, option.Name))
+			return
+		}
+
+		if ptr.IsNil() {
+			schema.Allocate(ptr)
+		} else {
+			copyPointer(ptr)
+		}
+		p.parseField(option.Elem, ptr.Elem(), join(name, option.Name))
+	}
+}
+
+// copyPointer replaces the pointer with a pointer to a shallow copy.
+func copyPointer(ptr reflect.Value) {
+	clone := reflect.New(ptr.Type().Elem())
+	clone.Elem().Set(ptr.Elem())
+	ptr.Set(clone)
+}
+
+func (p *parser) parsePointer(f *schema.Field, rv reflect.Value, name string) {
+	set := !rv.IsNil()
+	if s, ok := p.value(name + suffixSet); ok {
+		set = (s == "true")
+	}
+
+	switch p.action {
+	case actionFor(actionSet, name):
+		set = true
+	case actionFor(actionClear, name):
+		set = false
+	case actionFor(actionReset, name):
+		schema.Allocate(rv)
+		return
+	}
+
+	if !set {
+		rv.SetZero()
+		return
+	}
+
+	if rv.IsNil() {
+		schema.Allocate(rv)
+	} else {
+		copyPointer(rv)
+	}
+
+	p.parseField(f.Elem, rv.Elem(), name)
+}
+
+func (p *parser) parseList(f *schema.Field, rv reflect.Value, name string) {
+	if rv.Kind() == reflect.Array {
+		// Arrays were already copied by value
+		for i := 0; i < rv.Len(); i++ {
+			p.parseField(f.Elem, rv.Index(i), join(name, strconv.Itoa(i)))
+		}
+		return
+	}
+
+	count := p.count(rv.Len(), name)
+
+	out := reflect.MakeSlice(rv.Type(), 0, count)
+	for i := 0; i < count; i++ {
+		elemName := join(name, strconv.Itoa(i))
+
+		elem := newElem(rv.Type().Elem())
+		if i < rv.Len() {
+			elem.Set(rv.Index(i))
+		}
+		p.parseField(f.Elem, elem, elemName)
+
+		if p.action == actionFor(actionRemove, elemName) {
+			continue
+		}
+		out = reflect.Append(out, elem)
+	}
+
+	if p.action == actionFor(actionAdd, name) {
+		out = reflect.Append(out, newElem(rv.Type().Elem()))
+	}
+
+	if out.Len() == 0 && rv.IsNil() {
+		return // Keep nil
+	}
+	rv.Set(out)
+}
+
+func (p *parser) parseMap(f *schema.Field, rv reflect.Value, name string) {
+	keys := sortedKeys(rv)
+	count := p.count(len(keys), name)
+
+	out := reflect.MakeMapWithSize(rv.Type(), count)
+	for i := 0; i < count; i++ {
+		entryName := join(name, strconv.Itoa(i))
+
+		key := newElem(rv.Type().Key())
+		val := newElem(rv.Type().Elem())
+		if i < len(keys) {
+			key.Set(keys[i])
+			val.Set(rv.MapIndex(keys[i]))
+		}
+
+		if schema.IsText(key.Type()) {
+			if s, ok := p.value(entryName + suffixKey); ok {
+				err := schema.ParseText(key, f.Tag, s)
+				if err != nil {
+					p.fail(entryName+suffixKey, err)
+				}
+			}
+		}
+
+		p.parseField(f.Elem, val, entryName)
+
+		if p.action == actionFor(actionRemove, entryName) {
+			continue
+		}
+
+		if out.MapIndex(key).IsValid() {
+			p.fail(entryName+suffixKey, errors.New("duplicate key"))
+			continue
+		}
+		out.SetMapIndex(key, val)
+	}
+
+	if p.action == actionFor(actionAdd, name) {
+		key := newElem(rv.Type().Key())
+		if out.MapIndex(key).IsValid() {
+			p.fail(name, errors.New("an entry with an empty key already exists"))
+		} else {
+			out.SetMapIndex(key, newElem(rv.Type().Elem()))
+		}
+	}
+
+	if out.Len() == 0 && rv.IsNil() {
+		return // Keep nil
+	}
+	rv.Set(out)
+}
+
+// count gets the number of slice elements or map entries in the form. It is
+// limited to the number of elements that were submitted, or the current
+// length, so that a forged length can't allocate a huge slice or map.
+func (p *parser) count(current int, name string) int {
+	s, ok := p.value(name + suffixLen)
+	if !ok {
+		return current
+	}
+
+	limit := p.submitted(name)
+	if limit < current {
+		limit = current
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > limit {
+		p.fail(name, fmt.Errorf("invalid length %q", s))
+		return current
+	}
+	return n
+}
+
+// submitted gets the number of slice elements or map entries that have any
+// value in the form, i.e. one more than the highest index.
+func (p *parser) submitted(name string) int {
+	prefix := join(name, "")
+
+	ret := 0
+	for key := range p.form {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		rest := key[len(prefix):]
+		if end := strings.IndexAny(rest, ".~"); end != -1 {
+			rest = rest[:end]
+		}
+		if idx, err := strconv.Atoi(rest); err == nil && idx >= ret {
+			ret = idx + 1
+		}
+	}
+	return ret
+}
+
+// newElem creates a new addressable value for a slice element or map entry,
+// reset to defaults if it implements autoconfig.Resetter.
+func newElem(t reflect.Type) reflect.Value {
+	ret := reflect.New(t)
+	if defaulter, ok := ret.Interface().(interface{ Reset() }); ok {
+		defaulter.Reset()
+	}
+	return ret.Elem()
+}
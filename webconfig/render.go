@@ -0,0 +1,420 @@
+package webconfig
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/mappu/autoconfig/schema"
+)
+
+// renderer writes the HTML form for a value.
+type renderer struct {
+	b     *bytes.Buffer
+	errs  map[string]string
+	owner reflect.Value // The struct that has the field being rendered, for EnumStringOptionsFor
+}
+
+func esc(s string) string {
+	return html.EscapeString(s)
+}
+
+func (r *renderer) printf(format string, args ...any) {
+	fmt.Fprintf(r.b, format, args...)
+}
+
+func (r *renderer) error(name string) {
+	if msg, ok := r.errs[name]; ok {
+		r.printf(`<span class="error">%s</span>`, esc(msg))
+	}
+}
+
+// row writes a labelled row around the widget.
+func (r *renderer) row(f *schema.Field, name string, widget func()) {
+	r.printf(`<div class="row"><label for="%s">%s</label><div class="input">`, esc(name), esc(f.Label))
+
+	if prefix := f.Tag.Get("yprefix"); prefix != "" {
+		r.printf("%s ", esc(prefix))
+	}
+	widget()
+	if suffix := f.Tag.Get("ysuffix"); suffix != "" {
+		r.printf(" %s", esc(suffix))
+	}
+
+	if f.Help != "" {
+		r.printf(`<span class="help">%s</span>`, esc(f.Help))
+	}
+	r.error(name)
+	r.b.WriteString("</div></div>\n")
+}
+
+func (r *renderer) button(action, name, text string) {
+	r.printf(`<button type="submit" name="%s" value="%s" formnovalidate>%s</button>`, actionName, esc(actionFor(action, name)), esc(text))
+}
+
+func (r *renderer) hidden(name, value string) {
+	r.printf(`<input type="hidden" name="%s" value="%s">`, esc(name), esc(value))
+}
+
+func (r *renderer) field(f *schema.Field, rv reflect.Value, name string) {
+	switch f.Kind {
+	case schema.KindHeader:
+		r.printf("<h3>%s</h3>\n", esc(f.Label))
+
+	case schema.KindStruct, schema.KindGroupBox:
+		if name == "" || f.Embedded && f.Kind == schema.KindStruct {
+			r.fields(f.Expand(), rv, name)
+			return
+		}
+		r.printf("<fieldset><legend>%s</legend>\n", esc(f.Label))
+		r.fields(f.Expand(), rv, name)
+		r.b.WriteString("</fieldset>\n")
+
+	case schema.KindTabGroup:
+		r.tabs(f.Expand(), rv, name)
+
+	case schema.KindOneOf:
+		r.oneOf(f.Expand(), rv, name)
+
+	case schema.KindPointer:
+		r.pointer(f, rv, name)
+
+	case schema.KindSlice, schema.KindArray:
+		r.list(f, rv, name)
+
+	case schema.KindMap:
+		r.mapEntries(f, rv, name)
+
+	default:
+		r.row(f, name, func() { r.input(f, rv, name) })
+	}
+}
+
+func (r *renderer) fields(f *schema.Field, rv reflect.Value, name string) {
+	group := ""
+	for _, child := range f.FormFields() {
+		// Consecutive fields with the same `ygroup` tag share a fieldset
+		if child.Group != group {
+			if group != "" {
+				r.b.WriteString("</fieldset>\n")
+			}
+			if child.Group != "" {
+				r.printf("<fieldset><legend>%s</legend>\n", esc(child.Group))
+			}
+			group = child.Group
+		}
+
+		childName := name
+		if !child.Embedded {
+			childName = join(name, child.Name)
+		}
+		r.child(rv, child, childName)
+	}
+	if group != "" {
+		r.b.WriteString("</fieldset>\n")
+	}
+}
+
+// child writes a field of the struct, which is its owner.
+func (r *renderer) child(rv reflect.Value, f *schema.Field, name string) {
+	prevOwner := r.owner
+	r.owner = rv.FieldByIndex(f.IndexPath[:len(f.IndexPath)-1])
+	defer func() { r.owner = prevOwner }()
+
+	r.field(f, rv.FieldByIndex(f.IndexPath), name)
+}
+
+// input writes the widget for a single value.
+func (r *renderer) input(f *schema.Field, rv reflect.Value, name string) {
+	id := esc(name)
+
+	switch f.Kind {
+	case schema.KindBool:
+		// Unchecked checkboxes are not submitted, so send a default first
+		r.hidden(name, "false")
+		checked := ""
+		if rv.Bool() {
+			checked = " checked"
+		}
+		r.printf(`<input type="checkbox" id="%s" name="%s" value="true"%s>`, id, id, checked)
+
+	case schema.KindFactor, schema.KindDuration:
+		current := schema.InitialFactor(f.Factors, rv.Int())
+		r.printf(`<input type="number" step="1" id="%s" name="%s" value="%d"> `, id, id, rv.Int()/f.Factors[current].Divisor)
+		r.printf(`<select name="%s" aria-label="Unit">`, esc(name+suffixUnit))
+		for i, fac := range f.Factors {
+			r.option(strconv.Itoa(i), fac.Label, i == current)
+		}
+		r.b.WriteString("</select>")
+
+	case schema.KindEnumList:
+		r.printf(`<select id="%s" name="%s">`, id, id)
+		for i, opt := range schema.EnumListOptions(f.Tag.Get("yenum")) {
+			r.option(opt, opt, int64(i) == rv.Int())
+		}
+		r.b.WriteString("</select>")
+
+	case schema.KindEnumString:
+		opts, ok := schema.EnumStringOptionsFor(r.owner, f.Name, f.Tag)
+		if !ok || schema.EnumEditable(f.Tag) {
+			r.text(f, rv, name, "text")
+			return
+		}
+		r.printf(`<select id="%s" name="%s">`, id, id)
+		for _, opt := range opts {
+			r.option(opt, opt, opt == rv.String())
+		}
+		r.b.WriteString("</select>")
+
+	case schema.KindMultiLineString, schema.KindBytes:
+		r.printf(`<textarea id="%s" name="%s" rows="5">%s</textarea>`, id, id, esc(schema.FormatText(rv, f.Tag)))
+
+	case schema.KindPassword:
+		// Never send the secret back to the browser, an empty submission
+		// keeps the existing value
+		placeholder := ""
+		if rv.String() != "" {
+			placeholder = ` placeholder="(unchanged)"`
+		}
+		r.printf(`<input type="password" id="%s" name="%s" value=""%s>`, id, id, placeholder)
+
+	case schema.KindInt, schema.KindUint:
+		r.text(f, rv, name, "number")
+
+	case schema.KindFixed:
+		r.printf(`<span id="%s">%s</span>`, id, esc(display(rv)))
+
+	case schema.KindCustom:
+		if !schema.IsText(f.Type) {
+			r.printf(`<span id="%s">%s</span>`, id, esc(display(rv)))
+			return
+		}
+		r.text(f, rv, name, "text")
+
+	default:
+		r.text(f, rv, name, "text")
+	}
+}
+
+func (r *renderer) text(f *schema.Field, rv reflect.Value, name string, inputType string) {
+	extra := ""
+	switch {
+	case inputType == "number":
+		extra = ` step="1"`
+		if min, ok := f.Tag.Lookup("ymin"); ok {
+			extra += ` min="` + esc(min) + `"`
+		}
+		if max, ok := f.Tag.Lookup("ymax"); ok {
+			extra += ` max="` + esc(max) + `"`
+		}
+	case f.Kind == schema.KindAddressPort:
+		extra = ` placeholder="host:port"`
+	case f.Kind == schema.KindFlags:
+		var labels []string
+		for _, fl := range f.Flags {
+			labels = append(labels, fl.Label)
+		}
+		extra = ` placeholder="` + esc(strings.Join(labels, "|")) + `"`
+	case f.Kind == schema.KindTime:
+		extra = ` placeholder="2006-01-02T15:04:05Z"`
+	}
+
+	r.printf(`<input type="%s" id="%s" name="%s" value="%s"%s>`, inputType, esc(name), esc(name), esc(schema.FormatText(rv, f.Tag)), extra)
+}
+
+func (r *renderer) option(value, label string, selected bool) {
+	sel := ""
+	if selected {
+		sel = " selected"
+	}
+	r.printf(`<option value="%s"%s>%s</option>`, esc(value), sel, esc(label))
+}
+
+// display formats a value that can't be edited.
+func display(rv reflect.Value) string {
+	if !rv.CanInterface() {
+		return ""
+	}
+	switch rv.Kind() {
+	case reflect.Func, reflect.Chan, reflect.Interface, reflect.UnsafePointer:
+		if rv.IsNil() {
+			return "Not configured"
+		}
+		return "Configured"
+	}
+	return fmt.Sprint(rv.Interface())
+}
+
+func (r *renderer) tabs(f *schema.Field, rv reflect.Value, name string) {
+	group := "~tab." + name
+
+	r.b.WriteString(`<div class="tabs">` + "\n")
+	for i, child := range f.FormFields() {
+		childName := join(name, child.Name)
+		tabID := group + "." + strconv.Itoa(i)
+
+		checked := ""
+		if i == 0 {
+			checked = " checked"
+		}
+		r.printf(`<input type="radio" name="%s" id="%s"%s><label for="%s">%s</label><div class="tab">`+"\n", esc(group), esc(tabID), checked, esc(tabID), esc(child.Label))
+
+		if child.Kind == schema.KindStruct {
+			// The tab already shows the label
+			r.fields(child.Expand(), rv.FieldByIndex(child.IndexPath), childName)
+		} else {
+			r.child(rv, child, childName)
+		}
+
+		r.b.WriteString("</div>\n")
+	}
+	r.b.WriteString("</div>\n")
+}
+
+func (r *renderer) oneOf(f *schema.Field, rv reflect.Value, name string) {
+	selectName := name + suffixOption
+	current := rv.Field(0).String()
+
+	r.printf("<fieldset><legend>%s</legend>\n", esc(f.Label))
+	r.row(&schema.Field{Label: "Type"}, selectName, func() {
+		r.printf(`<select id="%s" name="%s" data-oneof>`, esc(selectName), esc(selectName))
+		for i, option := range f.FormFields() {
+			r.option(option.Name, option.Label, option.Name == current || (current == "" && i == 0))
+		}
+		r.b.WriteString("</select>")
+	})
+
+	for _, option := range f.FormFields() {
+		r.printf(`<fieldset data-oneof="%s" data-option="%s"><legend>%s</legend>`+"\n", esc(selectName), esc(option.Name), esc(option.Label))
+
+		optionName := join(name, option.Name)
+		ptr := rv.Field(option.Index)
+		if option.Kind != schema.KindPointer {
+			r.field(option, ptr, optionName)
+
+		} else {
+			// Show the defaults for options that are not selected
+			child := option.Elem
+			if ptr.IsNil() {
+				ptr = reflect.New(ptr.Type()).Elem()
+				schema.Allocate(ptr)
+			}
+			if child.Kind == schema.KindStruct {
+				r.fields(child.Expand(), ptr.Elem(), optionName)
+			} else {
+				r.field(child, ptr.Elem(), optionName)
+			}
+		}
+
+		r.b.WriteString("</fieldset>\n")
+	}
+
+	r.b.WriteString("</fieldset>\n")
+}
+
+func (r *renderer) pointer(f *schema.Field, rv reflect.Value, name string) {
+	if rv.IsNil() {
+		r.row(f, name, func() {
+			r.hidden(name+suffixSet, "false")
+			r.printf(`<span id="%s">Not configured</span> `, esc(name))
+			r.button(actionSet, name, "Configure")
+		})
+		return
+	}
+
+	r.printf("<fieldset><legend>%s</legend>\n", esc(f.Label))
+	r.hidden(name+suffixSet, "true")
+
+	child := f.Elem
+	if child.Kind == schema.KindStruct {
+		r.fields(child.Expand(), rv.Elem(), name)
+	} else {
+		r.field(child, rv.Elem(), name)
+	}
+
+	r.b.WriteString(`<div class="buttons">`)
+	if _, ok := rv.Interface().(interface{ Reset() }); ok {
+		r.button(actionReset, name, "Reset to defaults")
+	}
+	r.button(actionClear, name, "Clear")
+	r.b.WriteString("</div>\n")
+	r.b.WriteString("</fieldset>\n")
+}
+
+func (r *renderer) list(f *schema.Field, rv reflect.Value, name string) {
+	isSlice := rv.Kind() == reflect.Slice
+
+	r.printf("<fieldset><legend>%s</legend>\n", esc(f.Label))
+	if isSlice {
+		r.hidden(name+suffixLen, strconv.Itoa(rv.Len()))
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+		elemName := join(name, strconv.Itoa(i))
+
+		elem := *f.Elem
+		elem.Label = strconv.Itoa(i + 1)
+
+		r.b.WriteString(`<div class="item"><div class="value">`)
+		r.field(&elem, rv.Index(i), elemName)
+		r.b.WriteString("</div>")
+		if isSlice {
+			r.button(actionRemove, elemName, "Remove")
+		}
+		r.b.WriteString("</div>\n")
+	}
+
+	if isSlice {
+		r.b.WriteString(`<div class="buttons">`)
+		r.button(actionAdd, name, "Add")
+		r.b.WriteString("</div>\n")
+	}
+	r.error(name)
+	r.b.WriteString("</fieldset>\n")
+}
+
+func (r *renderer) mapEntries(f *schema.Field, rv reflect.Value, name string) {
+	keys := sortedKeys(rv)
+	editableKeys := schema.IsText(rv.Type().Key())
+
+	r.printf("<fieldset><legend>%s</legend>\n", esc(f.Label))
+	r.hidden(name+suffixLen, strconv.Itoa(len(keys)))
+
+	for i, key := range keys {
+		entryName := join(name, strconv.Itoa(i))
+
+		// Map values are not addressable
+		val := reflect.New(rv.Type().Elem()).Elem()
+		val.Set(rv.MapIndex(key))
+
+		keyField := schema.Field{Label: "Key", Kind: schema.KindFixed, Type: key.Type(), Tag: f.Tag}
+		if editableKeys {
+			keyField.Kind = schema.KindString
+		}
+
+		valField := *f.Elem
+		valField.Label = "Value"
+
+		r.b.WriteString(`<div class="item"><div class="value">`)
+		r.row(&keyField, entryName+suffixKey, func() {
+			if editableKeys {
+				r.text(&keyField, key, entryName+suffixKey, "text")
+			} else {
+				r.printf(`<span id="%s">%s</span>`, esc(entryName+suffixKey), esc(display(key)))
+			}
+		})
+		r.field(&valField, val, entryName)
+		r.b.WriteString("</div>")
+		r.button(actionRemove, entryName, "Remove")
+		r.b.WriteString("</div>\n")
+	}
+
+	r.b.WriteString(`<div class="buttons">`)
+	r.button(actionAdd, name, "Add")
+	r.b.WriteString("</div>\n")
+	r.error(name)
+	r.b.WriteString("</fieldset>\n")
+}
@@ -0,0 +1,202 @@
+// Package webconfig serves a configurable struct as an HTML form, for editing
+// the configuration from a web browser.
+//
+// It supports the same struct types and tags as autoconfig.MakeConfigArea, and
+// applies submitted values with the same validation rules, but it does not
+// depend on Qt.
+package webconfig
+
+import (
+	"bytes"
+	"html"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync"
+
+	"github.com/mappu/autoconfig/schema"
+)
+
+// Handler is an http.Handler that shows the struct as an HTML form (GET), and
+// applies the submitted form back to the struct (POST).
+//
+// Submitted values are only applied if they are all valid. Otherwise, the form
+// is shown again with an explanation next to each invalid field.
+//
+// Form submissions from other sites are rejected, by checking the Origin or
+// Referer header against the request's Host. Behind a reverse proxy, the proxy
+// must forward the original Host header.
+//
+// Requests are handled one at a time. If any other goroutine accesses the
+// struct, it must synchronise with the onFinished callback.
+type Handler struct {
+	mu         sync.Mutex
+	rv         reflect.Value
+	root       *schema.Field
+	title      string
+	onFinished func()
+}
+
+// NewHandler creates a Handler for the configurable struct, which must be a
+// non-nil pointer. The onFinished callback is called after every successful
+// save, and may be nil. The options change which fields are shown, e.g.
+// schema.HideJSONIgnored.
+func NewHandler(ct any, title string, onFinished func(), opts ...schema.LayoutOption) *Handler {
+	rv := reflect.ValueOf(ct)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		panic("webconfig: expected a non-nil pointer to a struct, got " + rv.Type().String()) // Programmer error
+	}
+
+	return &Handler{
+		rv:         rv,
+		root:       schema.DescribeType(rv.Type(), opts...).Root,
+		title:      title,
+		onFinished: onFinished,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		h.writePage(w, http.StatusOK, h.rv.Elem(), nil, "")
+
+	case http.MethodPost:
+		if !sameOrigin(r) {
+			http.Error(w, "Cross-origin request rejected", http.StatusForbidden)
+			return
+		}
+
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Work on a copy, so that nothing is applied unless it is all valid
+		work := reflect.New(h.rv.Type().Elem()).Elem()
+		work.Set(h.rv.Elem())
+
+		p := parser{
+			form:   r.PostForm,
+			action: r.PostForm.Get(actionName),
+			errs:   make(map[string]string),
+		}
+		p.parseField(h.root, work, "")
+
+		if p.action != "" {
+			// Add, remove, etc: show the updated form without saving
+			h.writePage(w, http.StatusOK, work, p.errs, "")
+
+		} else if len(p.errs) > 0 {
+			h.writePage(w, http.StatusBadRequest, work, p.errs, "Some values are invalid, and nothing was saved.")
+
+		} else {
+			h.rv.Elem().Set(work)
+			if h.onFinished != nil {
+				h.onFinished()
+			}
+			h.writePage(w, http.StatusOK, h.rv.Elem(), nil, "Saved.")
+		}
+
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// sameOrigin checks that a POST was submitted from a page on the same host,
+// and not from a form on another site (CSRF). Requests without an Origin or
+// Referer header, e.g. from scripts, are allowed, unless the browser reports
+// that they are cross-site.
+func sameOrigin(r *http.Request) bool {
+	src := r.Header.Get("Origin")
+	if src == "" {
+		src = r.Header.Get("Referer")
+	}
+	if src == "" {
+		return r.Header.Get("Sec-Fetch-Site") != "cross-site"
+	}
+
+	u, err := url.Parse(src)
+	return err == nil && u.Host == r.Host
+}
+
+func (h *Handler) writePage(w http.ResponseWriter, status int, rv reflect.Value, errs map[string]string, message string) {
+	var buf bytes.Buffer
+
+	buf.WriteString(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1">
+<title>` + html.EscapeString(h.title) + `</title>
+<style>` + pageStyle + `</style>
+</head><body>
+<h1>` + html.EscapeString(h.title) + `</h1>
+`)
+
+	if message != "" {
+		class := "message"
+		if len(errs) > 0 {
+			class = "message error"
+		}
+		buf.WriteString(`<p class="` + class + `">` + html.EscapeString(message) + "</p>\n")
+	}
+
+	// The first submit button is used when pressing Enter in a text field, so
+	// it must be Save and not e.g. a Remove button
+	buf.WriteString(`<form method="post">
+<button type="submit" class="default" tabindex="-1" aria-hidden="true"></button>
+`)
+
+	rr := renderer{b: &buf, errs: errs}
+	rr.field(h.root, rv, "")
+
+	buf.WriteString(`<div class="buttons"><button type="submit">Save</button></div>
+</form>
+<script>` + pageScript + `</script>
+</body></html>
+`)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+const pageStyle = `
+body { font-family: sans-serif; max-width: 50em; margin: 1em auto; padding: 0 1em; }
+.row { display: flex; gap: 1em; margin: 0.4em 0; align-items: baseline; }
+.row > label { flex: 0 0 12em; text-align: right; }
+.row > .input { flex: 1; }
+.row > .input input[type=text], .row > .input input[type=password], .row > .input textarea { width: 100%; box-sizing: border-box; }
+.help { display: block; color: #666; font-size: 0.9em; }
+.error { color: #b00; }
+.message { padding: 0.5em; background: #eef; }
+.message.error { background: #fee; }
+fieldset { margin: 0.5em 0; }
+.item { display: flex; gap: 0.5em; align-items: flex-start; }
+.item > .value { flex: 1; }
+.tabs { display: flex; flex-wrap: wrap; }
+.tabs > input { display: none; }
+.tabs > label { padding: 0.4em 1em; border: 1px solid #ccc; border-bottom: none; cursor: pointer; }
+.tabs > input:checked + label { font-weight: bold; background: #eee; }
+.tabs > .tab { order: 1; width: 100%; display: none; border: 1px solid #ccc; padding: 0.5em; }
+.tabs > input:checked + label + .tab { display: block; }
+.default { position: absolute; left: -9999px; }
+.buttons { margin-top: 1em; text-align: right; }
+`
+
+// pageScript shows only the selected option of each OneOf. Without scripting,
+// all options are shown, and only the selected one is saved.
+const pageScript = `
+document.querySelectorAll("select[data-oneof]").forEach(function(sel) {
+	function update() {
+		document.querySelectorAll("fieldset[data-oneof]").forEach(function(fs) {
+			if (fs.dataset.oneof !== sel.name) return;
+			fs.hidden = fs.disabled = (fs.dataset.option !== sel.value);
+		});
+	}
+	sel.addEventListener("change", update);
+	update();
+});
+`
@@ -0,0 +1,319 @@
+package webconfig
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mappu/autoconfig/schema"
+)
+
+type testOptional struct {
+	Enabled bool
+	Level   int
+}
+
+func (t *testOptional) Reset() {
+	t.Level = 3
+}
+
+type testConfig struct {
+	Name     string `ylabel:"Display name" yhelp:"Shown in the title bar"`
+	Port     uint16
+	Debug    bool `ygroup:"Diagnostics"`
+	Timeout  time.Duration
+	Peers    []string
+	Labels   map[string]int
+	Optional *testOptional
+	Network  struct {
+		DataDir string
+	}
+	Callback func()
+}
+
+func post(t *testing.T, h http.Handler, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandlerGet(t *testing.T) {
+	cfg := testConfig{Name: "<server>", Timeout: 90 * time.Second, Peers: []string{"a", "b"}}
+	h := NewHandler(&cfg, "Settings", nil)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET: got status %d", rec.Code)
+	}
+
+	body := rec.Body.String()
+	for _, expect := range []string{
+		`<label for="Name">Display name</label>`,
+		`value="&lt;server&gt;"`, // Escaped
+		`Shown in the title bar`,
+		`name="Timeout" value="90"`,
+		`<option value="3" selected>seconds</option>`,
+		`name="Peers~len" value="2"`,
+		`name="Peers.1" value="b"`,
+		`name="~action" value="set:Optional"`,
+		`name="Network.DataDir"`,
+		`<legend>Diagnostics</legend>`,
+	} {
+		if !strings.Contains(body, expect) {
+			t.Errorf("GET: expected body to contain %q", expect)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE: got status %d", rec.Code)
+	}
+}
+
+func TestHandlerPost(t *testing.T) {
+	saved := 0
+	cfg := testConfig{Name: "old", Peers: []string{"a", "b", "c"}, Labels: map[string]int{"x": 1}}
+	h := NewHandler(&cfg, "Settings", func() { saved++ })
+
+	rec := post(t, h, url.Values{
+		"Name":             {"new"},
+		"Port":             {"8080"},
+		"Debug":            {"false", "true"}, // Hidden default, then checkbox
+		"Timeout":          {"5"},
+		"Timeout~unit":     {"4"}, // minutes
+		"Peers~len":        {"2"},
+		"Peers.0":          {"p"},
+		"Peers.1":          {"q"},
+		"Labels~len":       {"1"},
+		"Labels.0~key":     {"y"},
+		"Labels.0":         {"2"},
+		"Optional~set":     {"true"},
+		"Optional.Enabled": {"false", "true"},
+		"Network.DataDir":  {"/tmp"},
+	})
+	if rec.Code != http.StatusOK || saved != 1 {
+		t.Fatalf("POST: got status %d, saved %d times", rec.Code, saved)
+	}
+
+	if cfg.Name != "new" || cfg.Port != 8080 || !cfg.Debug || cfg.Timeout != 5*time.Minute || cfg.Network.DataDir != "/tmp" {
+		t.Errorf("POST: got %#v", cfg)
+	}
+	if !reflect.DeepEqual(cfg.Peers, []string{"p", "q"}) {
+		t.Errorf("Peers: got %v", cfg.Peers)
+	}
+	if !reflect.DeepEqual(cfg.Labels, map[string]int{"y": 2}) {
+		t.Errorf("Labels: got %v", cfg.Labels)
+	}
+	if cfg.Optional == nil || !cfg.Optional.Enabled || cfg.Optional.Level != 3 {
+		t.Errorf("Optional: got %#v", cfg.Optional)
+	}
+
+	// Fields that are not submitted are unchanged
+	rec = post(t, h, url.Values{"Name": {"partial"}})
+	if rec.Code != http.StatusOK || cfg.Name != "partial" || cfg.Port != 8080 || len(cfg.Peers) != 2 {
+		t.Errorf("partial POST: got status %d, %#v", rec.Code, cfg)
+	}
+}
+
+func TestHandlerValidation(t *testing.T) {
+	cfg := testConfig{Name: "old", Peers: []string{"a"}}
+	h := NewHandler(&cfg, "Settings", func() { t.Errorf("unexpected save") })
+
+	rec := post(t, h, url.Values{
+		"Name":      {"new"},
+		"Port":      {"70000"}, // Out of range for uint16
+		"Peers~len": {"1"},
+		"Peers.0":   {"changed"},
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("POST: got status %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `class="error"`) {
+		t.Errorf("POST: expected an error message")
+	}
+
+	// Nothing is applied, and the original slice is not modified
+	if cfg.Name != "old" || cfg.Peers[0] != "a" {
+		t.Errorf("POST: expected no changes, got %#v", cfg)
+	}
+}
+
+func TestHandlerActions(t *testing.T) {
+	cfg := testConfig{Peers: []string{"a", "b"}}
+	h := NewHandler(&cfg, "Settings", func() { t.Errorf("unexpected save") })
+
+	type testCase struct {
+		action string
+		expect []string
+	}
+
+	cases := []testCase{
+		{"add:Peers", []string{`name="Peers~len" value="3"`, `name="Peers.2" value=""`}},
+		{"remove:Peers.0", []string{`name="Peers~len" value="1"`, `name="Peers.0" value="b"`}},
+		{"set:Optional", []string{`name="Optional~set" value="true"`, `name="Optional.Level" value="3"`}},
+		{"add:Labels", []string{`name="Labels~len" value="1"`, `name="Labels.0~key" value=""`}},
+	}
+
+	for _, tc := range cases {
+		rec := post(t, h, url.Values{"~action": {tc.action}})
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: got status %d", tc.action, rec.Code)
+		}
+		for _, expect := range tc.expect {
+			if !strings.Contains(rec.Body.String(), expect) {
+				t.Errorf("%s: expected body to contain %q", tc.action, expect)
+			}
+		}
+	}
+
+	// Actions only change the form, not the struct
+	if len(cfg.Peers) != 2 || cfg.Optional != nil || cfg.Labels != nil {
+		t.Errorf("actions: expected no changes, got %#v", cfg)
+	}
+}
+
+func TestHandlerForgedLength(t *testing.T) {
+	cfg := testConfig{Peers: []string{"a"}}
+	h := NewHandler(&cfg, "Settings", func() { t.Errorf("unexpected save") })
+
+	// After two "Add" actions, the form has more elements than the struct
+	rec := post(t, h, url.Values{"~action": {"add:Peers"}, "Peers~len": {"3"}, "Peers.0": {"a"}, "Peers.1": {""}, "Peers.2": {""}})
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `name="Peers~len" value="4"`) {
+		t.Errorf("submitted elements: got status %d", rec.Code)
+	}
+
+	rec = post(t, h, url.Values{"Peers~len": {"100000000000"}, "Labels~len": {"100000000000"}})
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid length") {
+		t.Errorf("forged length: got status %d", rec.Code)
+	}
+}
+
+func TestHandlerCrossOrigin(t *testing.T) {
+	cfg := testConfig{}
+	h := NewHandler(&cfg, "Settings", nil)
+
+	for _, header := range []http.Header{
+		{"Origin": {"https://attacker.example"}},
+		{"Origin": {"null"}},
+		{"Referer": {"https://attacker.example/form.html"}},
+		{"Sec-Fetch-Site": {"cross-site"}},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("Name=changed"))
+		req.Header = header
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusForbidden || cfg.Name != "" {
+			t.Errorf("%v: got status %d, name %q", header, rec.Code, cfg.Name)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("Name=changed"))
+	req.Header.Set("Origin", "http://example.com") // Same as httptest's Host
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || cfg.Name != "changed" {
+		t.Errorf("same origin: got status %d, name %q", rec.Code, cfg.Name)
+	}
+}
+
+func TestHandlerHideJSONIgnored(t *testing.T) {
+	cfg := struct {
+		Name     string
+		Internal string `json:"-"`
+	}{}
+
+	for _, hide := range []bool{false, true} {
+		var opts []schema.LayoutOption
+		if hide {
+			opts = append(opts, schema.HideJSONIgnored())
+		}
+		h := NewHandler(&cfg, "Settings", nil, opts...)
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if got := strings.Contains(rec.Body.String(), `name="Internal"`); got == hide {
+			t.Errorf("hide %v: got Internal field %v", hide, got)
+		}
+	}
+}
+
+func TestHandlerPassword(t *testing.T) {
+	cfg := struct {
+		User     string
+		Password string
+	}{User: "admin", Password: "hunter2"}
+	h := NewHandler(&cfg, "Settings", nil)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	body := rec.Body.String()
+	if strings.Contains(body, "hunter2") {
+		t.Errorf("GET: body contains the password")
+	}
+	if !strings.Contains(body, `type="password" id="Password" name="Password" value=""`) {
+		t.Errorf("GET: expected an empty password input")
+	}
+
+	// An empty submission keeps the existing value
+	post(t, h, url.Values{"User": {"root"}, "Password": {""}})
+	if cfg.User != "root" || cfg.Password != "hunter2" {
+		t.Errorf("empty POST: got %#v", cfg)
+	}
+
+	post(t, h, url.Values{"Password": {"s3cret"}})
+	if cfg.Password != "s3cret" {
+		t.Errorf("POST: got %#v", cfg)
+	}
+}
+
+type TabCommon struct {
+	Shared int
+}
+
+type testTabs struct {
+	TabCommon
+	First struct {
+		A int
+	}
+	Second struct {
+		B int
+	} `yorder:"-1"`
+	Hidden int `yhide:""`
+}
+
+func TestRenderTabs(t *testing.T) {
+	// A TabGroup needs the autoconfig package, so only the kind is changed
+	f := schema.DescribeType(reflect.TypeOf(testTabs{})).Root
+	f.Kind = schema.KindTabGroup
+
+	r := renderer{b: &bytes.Buffer{}}
+	r.field(f, reflect.ValueOf(testTabs{}), "")
+	body := r.b.String()
+
+	first, second := strings.Index(body, `name="First.A"`), strings.Index(body, `name="Second.B"`)
+	if first == -1 || second == -1 || second > first {
+		t.Errorf("expected the tabs in yorder order, got %q", body)
+	}
+	if !strings.Contains(body, `name="Shared"`) {
+		t.Errorf("expected the embedded field as a tab")
+	}
+	if strings.Contains(body, "Hidden") {
+		t.Errorf("expected the yhide field to be hidden")
+	}
+}
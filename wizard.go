@@ -0,0 +1,182 @@
+package autoconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mappu/autoconfig/schema"
+	qt "github.com/mappu/miqt/qt6"
+)
+
+// Validator is a type that can check its own value.
+// OpenWizard checks the field on each page before moving to the next page, and
+// the whole struct before finishing. Use with either value or pointer receiver.
+type Validator interface {
+	Validate() error
+}
+
+// WizardSkipper is a struct that can skip some of its pages in OpenWizard,
+// depending on the values entered so far. The name is the struct field name.
+// Use with either value or pointer receiver.
+type WizardSkipper interface {
+	SkipWizardPage(name string) bool
+}
+
+// OpenWizard opens the struct for editing in a new QWizard, with a page for
+// each top-level field (or each TabGroup member), in display order.
+//
+// Pages can be skipped with the `yskipif` tag (e.g. `yskipif:"Advanced=false"`)
+// or the WizardSkipper interface. Changes are only saved into the supplied
+// struct, and onFinished called, if the user finishes the wizard.
+func OpenWizard(ct ConfigurableStruct, parent *qt.QWidget, title string, onFinished func(), opts ...Option) {
+	rv := reflect.ValueOf(ct)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		panic("OpenWizard: expected a non-nil pointer to a struct")
+	}
+
+	// Edit a copy, so that cancelling has no effect
+	original := rv.Elem()
+	work := schema.Clone(original)
+	workPtr := work.Addr()
+	form := newFormContext(&workPtr, opts)
+
+	wizard := qt.NewQWizard(parent)
+	wizard.SetWindowTitle(title)
+	wizard.SetAttribute(qt.WA_DeleteOnClose)
+
+	fields := schema.FormFields(work.Type(), form.layout()...)
+	pageIds := make([]int, len(fields))
+	var editors []*Editor
+
+	for i, field := range fields {
+		page := qt.NewQWizardPage2()
+		page.SetTitle(field.Label)
+		if field.Help != "" {
+			page.SetSubTitle(field.Help)
+		}
+
+		fieldValue := work.FieldByIndex(field.IndexPath)
+
+		// The page title already shows the label, except for single values
+		label := ""
+		if schema.Classify(field.Type, field.Tag) != schema.KindStruct {
+			label = field.Label
+		}
+
+		formArea := newFormArea()
+		editor := newEditor(&fieldValue, formArea, field.Tag, label, form.nested())
+		editors = append(editors, editor)
+
+		vbox := qt.NewQVBoxLayout(page.QWidget)
+		vbox.SetContentsMargins(0, 0, 0, 0)
+		vbox.AddWidget(newScrollArea(page.QWidget, formArea).QWidget)
+
+		idx := i
+		page.OnValidatePage(func(super func() bool) bool {
+			editor.Save()
+
+			// Check the whole struct on the last page that is shown
+			err := validateValue(fieldValue)
+			if err == nil && wizardNext(work, fields, idx) == -1 {
+				err = validateValue(work)
+			}
+			if err != nil {
+				qt.QMessageBox_Warning(page.QWidget, tr("Invalid value"), err.Error())
+				return false
+			}
+			return true
+		})
+
+		page.OnNextId(func(super func() int) int {
+			editor.Save() // The skip decision may depend on this page
+			if next := wizardNext(work, fields, idx); next != -1 {
+				return pageIds[next]
+			}
+			return -1 // Finish
+		})
+
+		pageIds[i] = wizard.AddPage(page)
+	}
+
+	// The first page may be skipped too
+	if first := wizardNext(work, fields, -1); first != -1 {
+		wizard.SetStartId(pageIds[first])
+	}
+
+	wizard.OnFinished(func(result int) {
+		if result != int(qt.QDialog__Accepted) {
+			return // Cancelled
+		}
+
+		for _, editor := range editors {
+			editor.Save()
+		}
+		original.Set(work)
+		onFinished()
+	})
+
+	wizard.Show()
+}
+
+// validateValue calls Validate, if the value implements Validator.
+func validateValue(rv reflect.Value) error {
+	if v, ok := rv.Interface().(Validator); ok {
+		return v.Validate()
+	}
+	if rv.CanAddr() {
+		if v, ok := rv.Addr().Interface().(Validator); ok {
+			return v.Validate()
+		}
+	}
+	return nil
+}
+
+// wizardNext finds the index of the next field after idx whose page is not
+// skipped, or -1 if there are none. Use -1 for idx to find the first page.
+func wizardNext(root reflect.Value, fields []*schema.Field, idx int) int {
+	for next := idx + 1; next < len(fields); next++ {
+		if !wizardSkip(root, fields[next]) {
+			return next
+		}
+	}
+	return -1
+}
+
+// wizardSkip checks whether the page for the field should be skipped.
+func wizardSkip(root reflect.Value, field *schema.Field) bool {
+	if skipper, ok := root.Interface().(WizardSkipper); ok && skipper.SkipWizardPage(field.Name) {
+		return true
+	}
+	if skipper, ok := root.Addr().Interface().(WizardSkipper); ok && skipper.SkipWizardPage(field.Name) {
+		return true
+	}
+
+	cond, ok := field.Tag.Lookup("yskipif")
+	if !ok {
+		return false
+	}
+
+	// Field=Value or Field!=Value
+	name, want, ok := strings.Cut(cond, "=")
+	if !ok {
+		panic("yskipif: expected Field=Value or Field!=Value, got " + cond)
+	}
+	negate := strings.HasSuffix(name, "!")
+	name = strings.TrimSuffix(name, "!")
+
+	ff, ok := root.Type().FieldByName(name)
+	if !ok {
+		panic("yskipif: no field named " + name)
+	}
+
+	target := root.FieldByIndex(ff.Index)
+	var got string
+	if schema.IsText(target.Type()) {
+		got = schema.FormatText(target, ff.Tag)
+	} else {
+		got = fmt.Sprint(target.Interface())
+	}
+
+	return (got == want) != negate
+}
@@ -0,0 +1,112 @@
+package autoconfig
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mappu/autoconfig/schema"
+)
+
+type testWizardNetwork struct {
+	Port int
+}
+
+func (n testWizardNetwork) Validate() error {
+	if n.Port == 0 {
+		return errors.New("port is required")
+	}
+	return nil
+}
+
+type testWizardStruct struct {
+	Advanced bool
+	Mode     EnumList              `yenum:"Client;;Server"`
+	Tuning   struct{ Workers int } `yskipif:"Advanced=false"`
+	Network  testWizardNetwork     `yskipif:"Mode!=Server"`
+	Extra    string
+}
+
+func (testWizardStruct) SkipWizardPage(name string) bool {
+	return name == "Extra"
+}
+
+func TestWizardSkip(t *testing.T) {
+	var ct testWizardStruct
+	rv := reflect.ValueOf(&ct).Elem()
+
+	skipped := func() []string {
+		var ret []string
+		for _, field := range schema.StructFields(rv.Type()) {
+			if wizardSkip(rv, field) {
+				ret = append(ret, field.Name)
+			}
+		}
+		return ret
+	}
+
+	if got, want := skipped(), []string{"Tuning", "Network", "Extra"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("skipped %v, want %v", got, want)
+	}
+
+	ct.Advanced = true
+	ct.Mode = 1
+	if got, want := skipped(), []string{"Extra"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("skipped %v, want %v", got, want)
+	}
+}
+
+func TestWizardValidate(t *testing.T) {
+	var ct testWizardStruct
+	network := reflect.ValueOf(&ct).Elem().FieldByName("Network")
+
+	if err := validateValue(network); err == nil {
+		t.Errorf("expected error for an empty port")
+	}
+
+	ct.Network.Port = 80
+	if err := validateValue(network); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+type testWizardStart struct {
+	Intro string `yskipif:"Quick=true"`
+	Quick bool
+	Name  string
+}
+
+func TestWizardNext(t *testing.T) {
+	var ct testWizardStruct
+	rv := reflect.ValueOf(&ct).Elem()
+	fields := schema.FormFields(rv.Type())
+
+	// Every page after Mode is skipped, so Mode is the last page, and the
+	// whole struct is checked there
+	if got := wizardNext(rv, fields, 1); got != -1 {
+		t.Errorf("after Mode: got page %d, want -1", got)
+	}
+
+	ct.Advanced = true
+	if got := wizardNext(rv, fields, 1); got != 2 {
+		t.Errorf("after Mode: got page %d, want 2", got)
+	}
+	if got := wizardNext(rv, fields, 2); got != -1 {
+		t.Errorf("after Tuning: got page %d, want -1", got)
+	}
+}
+
+func TestWizardNextStart(t *testing.T) {
+	ct := testWizardStart{Quick: true}
+	rv := reflect.ValueOf(&ct).Elem()
+	fields := schema.FormFields(rv.Type())
+
+	if got := wizardNext(rv, fields, -1); got != 1 {
+		t.Errorf("start: got page %d, want 1", got)
+	}
+
+	ct.Quick = false
+	if got := wizardNext(rv, fields, -1); got != 0 {
+		t.Errorf("start: got page %d, want 0", got)
+	}
+}